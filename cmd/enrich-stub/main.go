@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"test-task/internal/services/enrich/enrichstub"
+	"time"
+)
+
+// Local stand-in of agify.io, genderize.io and nationalize.io.
+// Point the app to it:
+//
+//	AGIFY_URL=http://localhost:8090/agify/
+//	GENDERIZE_URL=http://localhost:8090/genderize/
+//	NATIONALIZE_URL=http://localhost:8090/nationalize/
+func main() {
+	addr := flag.String("addr", "localhost:8090", "listen address")
+	latency := flag.Duration("latency", 0, "delay of every response")
+	errorRate := flag.Float64("error-rate", 0, "share of 500 responses [0,1]")
+	rateLimitRate := flag.Float64("ratelimit-rate", 0, "share of 429 responses [0,1]")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of error injection")
+	quota := flag.Int("quota", 0, "requests to every API per quota-window, 0 - unlimited")
+	quotaWindow := flag.Duration("quota-window", 24*time.Hour, "quota reset period")
+	flag.Parse()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	stub := enrichstub.New(enrichstub.Options{
+		Latency:       *latency,
+		ErrorRate:     *errorRate,
+		RateLimitRate: *rateLimitRate,
+		Seed:          *seed,
+		Quota:         *quota,
+		QuotaWindow:   *quotaWindow,
+	})
+
+	log.Info("enrich stub started", "addr", *addr,
+		"agify", "http://"+*addr+enrichstub.AgifyPath,
+		"genderize", "http://"+*addr+enrichstub.GenderizePath,
+		"nationalize", "http://"+*addr+enrichstub.NationalizePath,
+	)
+
+	if err := http.ListenAndServe(*addr, stub); err != nil {
+		log.Error("enrich stub stopped", "err", err)
+
+		os.Exit(1)
+	}
+}
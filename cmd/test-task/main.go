@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,6 +13,7 @@ import (
 	"test-task/internal/api"
 	"test-task/internal/config"
 	"test-task/internal/logger"
+	"test-task/internal/services/enrich"
 	"time"
 
 	"test-task/internal/storage/postgres"
@@ -31,6 +33,11 @@ const (
 
 // @host localhost:8080
 // @BasePath /api/v1/
+
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description "Bearer <ADMIN_TOKEN>"
 func main() {
 
 	ctx := context.Background()
@@ -55,11 +62,39 @@ func main() {
 	}
 
 	// init api with services
-	api := api.New(log, storage)
+	api, err := api.New(log, storage, cfg)
+	if err != nil {
+		log.Error("can't init api", "err", err)
+
+		os.Exit(1)
+	}
+
+	// enrich people created in async mode, pending people of previous runs too
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+
+	workers := enrich.NewWorkers(log, api.Enricher, storage, enrich.WorkerConfig{
+		Workers:      cfg.EnrichWorkers,
+		PollInterval: cfg.EnrichPollInterval,
+		Lease:        cfg.EnrichLease,
+		MaxAttempts:  cfg.EnrichMaxAttempts,
+		RetryDelay:   cfg.EnrichRetryDelay,
+	})
+
+	go func() {
+		defer close(workersDone)
+
+		workers.Run(workersCtx)
+	}()
+
+	// contexts of requests are canceled on forced shutdown, so outbound calls stop too
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
 	srv := http.Server{
-		Addr:    cfg.ServerHost + ":" + cfg.ServerPort,
-		Handler: api.Router,
+		Addr:        cfg.ServerHost + ":" + cfg.ServerPort,
+		Handler:     api.Router,
+		BaseContext: func(net.Listener) context.Context { return requestsCtx },
 	}
 
 	//graceful shutdown
@@ -103,12 +138,16 @@ func main() {
 
 		if err := srv.Shutdown(ctx); err != nil {
 			log.Error("server graceful shutdown failed", "err", err)
+			cancelRequests()
 			err = srv.Close()
 			if err != nil {
 				log.Error("forced shutdown failed", "err", err)
 			}
 		}
 
+		stopWorkers()
+		<-workersDone
+
 		storage.Close()
 
 		log.Info(InfoDbClosed)
@@ -15,6 +15,88 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/enrichment/breakers": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "State of circuit breaker of every enrichment provider: closed, open (calls fail fast) or half-open (trial calls).\nEmpty if breakers are disabled (ENRICH_BREAKER_FAILURES=0)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Enrichment circuit breakers",
+                "operationId": "breakers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/breakers.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin API is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/people/purge": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Remove forever people soft deleted longer than retention (PURGE_RETENTION)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Purge deleted people",
+                "operationId": "purge",
+                "responses": {
+                    "200": {
+                        "description": "OK - number of purged people",
+                        "schema": {
+                            "$ref": "#/definitions/purge.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin API is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/people": {
             "get": {
                 "description": "get accounts by filters",
@@ -64,6 +146,55 @@ const docTemplate = `{
                         "name": "patronymic",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "example": "ol",
+                        "description": "name starts with, case-insensitive",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "le",
+                        "description": "name contains, case-insensitive",
+                        "name": "name_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "iva",
+                        "description": "surname starts with, case-insensitive",
+                        "name": "surname_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "petr",
+                        "description": "surname contains, case-insensitive",
+                        "name": "surname_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "petr",
+                        "description": "patronymic starts with, case-insensitive",
+                        "name": "patronymic_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "ovich",
+                        "description": "patronymic contains, case-insensitive",
+                        "name": "patronymic_contains",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "description": "exact name, surname and patronymic filters ignore case",
+                        "name": "ignore_case",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "example": 32,
@@ -98,6 +229,26 @@ const docTemplate = `{
                         "description": "person filter by nationality",
                         "name": "nationality",
                         "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "example": 0.1,
+                        "description": "with nationality: also match people having it as nationalize candidate with probability not less",
+                        "name": "nationality_probability",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from meta next_cursor/prev_cursor. Switches to cursor pagination, page is ignored. Empty value - first page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "surname,-age,created_at",
+                        "description": "comma separated sort fields, '-' prefix for descending order. Allowed: id, name, surname, patronymic, age, gender, nationality, created_at, updated_at",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -128,7 +279,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creating, enriching and saving new user",
+                "description": "Creating, enriching and saving new user.\nIn async mode (ENRICH_ASYNC) person is saved with pending EnrichmentStatus and enriched in background,\nstatus is done or failed when it's finished.\nWith ENRICH_PARTIAL person is saved if some providers failed: their attributes are null with failed status in enrichment\nand EnrichmentStatus is pending till they are enriched in background.\nAge and gender are estimated for country if it's given, otherwise for nationality found first (ENRICH_LOCALIZED)",
                 "consumes": [
                     "application/json"
                 ],
@@ -158,6 +309,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/create.Response"
                         }
                     },
+                    "202": {
+                        "description": "Accepted - person is saved, enrichment is pending",
+                        "schema": {
+                            "$ref": "#/definitions/create.Response"
+                        }
+                    },
                     "400": {
                         "description": "Invalid input"
                     },
@@ -167,7 +324,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete the user by id",
+                "description": "Soft delete the user by id. Person can be restored until purge",
                 "consumes": [
                     "application/json"
                 ],
@@ -213,7 +370,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Update any field of person\nNeed at least one field to update",
+                "description": "Update any field of person\nNeed at least one field to update\nAge, gender and nationality set by update are not enriched anymore: their enrichment is dropped and person leaves review queue if they were unknown\nNationality set by update drops nationality candidates of provider\nSend ETag of GET /people/{id} in If-Match to update only not changed since person. New ETag is returned in ETag header",
                 "consumes": [
                     "application/json"
                 ],
@@ -241,6 +398,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"3\"",
+                        "description": "ETag of person version the update is based on, list of ETags or *. Weak ETags never match",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -248,96 +412,638 @@ const docTemplate = `{
                         "description": "OK - Returns a person fields with update",
                         "schema": {
                             "$ref": "#/definitions/update.Response"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of updated person"
+                            }
                         }
                     },
                     "400": {
                         "description": "Invalid input"
                     },
+                    "412": {
+                        "description": "Person was changed since If-Match version",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal error"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "create.Request": {
-            "type": "object",
-            "required": [
-                "name",
-                "surname"
-            ],
-            "properties": {
-                "name": {
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 2,
-                    "example": "Alexander"
-                },
-                "patronymic": {
-                    "description": "required",
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 2,
-                    "example": "Petrovich"
-                },
-                "surname": {
-                    "description": "required",
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 2,
-                    "example": "Sidorov"
-                }
-            }
         },
-        "create.Response": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "response": {
-                    "$ref": "#/definitions/response.Response"
+        "/people/batch": {
+            "post": {
+                "description": "Creating, enriching and saving array of users at once.\nInvalid or not enriched items are reported in items and don't stop others.\nNames are enriched with name[] requests of up to 10 names",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Create many users",
+                "operationId": "batch",
+                "parameters": [
+                    {
+                        "description": "Array of person basic info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/create.Request"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK - per item ids and errors",
+                        "schema": {
+                            "$ref": "#/definitions/batch.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Too many items",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
                 }
             }
         },
-        "list.Response": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Person"
+        "/people/review": {
+            "get": {
+                "description": "people with enriched values below confidence threshold (ENRICH_*_MIN_*). Such attributes are empty, enrichment keeps rejected value with its probability and count under unknown status\nPerson leaves the queue when all unknown attributes are set by PATCH /people/{id}",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "People to review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "num of page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 3,
+                        "description": "limit wrties on page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/list.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
-                },
-                "meta": {
-                    "$ref": "#/definitions/types.Meta"
-                },
-                "response": {
-                    "$ref": "#/definitions/response.Response"
                 }
             }
         },
-        "models.Person": {
-            "type": "object",
-            "properties": {
-                "age": {
-                    "type": "integer"
-                },
-                "gender": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "nationality": {
-                    "type": "string"
-                },
-                "patronymic": {
-                    "type": "string"
-                },
+        "/people/search": {
+            "get": {
+                "description": "free text search by name, surname and patronymic in any order. Every word matches as prefix. Results are ordered by relevance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Search people",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "Ivanov Petr",
+                        "description": "search text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "num of page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 3,
+                        "description": "limit wrties on page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/list.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/{id}": {
+            "get": {
+                "description": "Get one person by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Get person",
+                "operationId": "get",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/get.Response"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "person version, use it in If-Match of PATCH"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Person not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/{id}/history": {
+            "get": {
+                "description": "All changes of person from the oldest: operation, old and new values, request ID, actor (unauthenticated X-Actor header as sent by client) and time.\nAvailable for deleted and purged people too, purge is the last record",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Person history",
+                "operationId": "history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/history.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "No history for person",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/people/{id}/restore": {
+            "post": {
+                "description": "Restore soft deleted person by id\nDelete and restore change person version, ETags issued before delete don't match anymore",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Restore",
+                "operationId": "restore",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Deleted person not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "batch.ItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                }
+            }
+        },
+        "batch.Response": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/batch.ItemResult"
+                    }
+                },
+                "response": {
+                    "$ref": "#/definitions/response.Response"
+                },
+                "saved": {
+                    "type": "integer"
+                }
+            }
+        },
+        "breakers.Response": {
+            "type": "object",
+            "properties": {
+                "breakers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/enrich.BreakerStatus"
+                    }
+                },
+                "response": {
+                    "$ref": "#/definitions/response.Response"
+                }
+            }
+        },
+        "create.Request": {
+            "type": "object",
+            "required": [
+                "name",
+                "surname"
+            ],
+            "properties": {
+                "country": {
+                    "description": "omitempty",
+                    "type": "string",
+                    "example": "RU"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2,
+                    "example": "Alexander"
+                },
+                "patronymic": {
+                    "description": "required",
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2,
+                    "example": "Petrovich"
+                },
+                "surname": {
+                    "description": "required",
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 2,
+                    "example": "Sidorov"
+                }
+            }
+        },
+        "create.Response": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "person": {
+                    "$ref": "#/definitions/models.Person"
+                },
+                "response": {
+                    "$ref": "#/definitions/response.Response"
+                }
+            }
+        },
+        "enrich.BreakerState": {
+            "type": "string",
+            "enum": [
+                "closed",
+                "open",
+                "half-open"
+            ],
+            "x-enum-varnames": [
+                "BreakerClosed",
+                "BreakerOpen",
+                "BreakerHalfOpen"
+            ]
+        },
+        "enrich.BreakerStatus": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "openedAt": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/enrich.BreakerState"
+                }
+            }
+        },
+        "get.Response": {
+            "type": "object",
+            "properties": {
+                "person": {
+                    "$ref": "#/definitions/models.Person"
+                },
+                "response": {
+                    "$ref": "#/definitions/response.Response"
+                }
+            }
+        },
+        "history.Response": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PersonChange"
+                    }
+                },
+                "response": {
+                    "$ref": "#/definitions/response.Response"
+                }
+            }
+        },
+        "list.Response": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Person"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/types.Meta"
+                },
+                "response": {
+                    "$ref": "#/definitions/response.Response"
+                }
+            }
+        },
+        "models.AttributeEnrichment": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fetched_at": {
+                    "type": "string"
+                },
+                "probability": {
+                    "type": "number"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NationalityCandidate": {
+            "type": "object",
+            "properties": {
+                "country_id": {
+                    "type": "string"
+                },
+                "probability": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Person": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "country_hint": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enrichment": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.AttributeEnrichment"
+                    }
+                },
+                "enrichment_status": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nationalities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NationalityCandidate"
+                    }
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
                 "surname": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PersonChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "changed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "new_values": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "old_values": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "integer"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "purge.Response": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "type": "integer"
+                },
+                "response": {
+                    "$ref": "#/definitions/response.Response"
                 }
             }
         },
@@ -362,9 +1068,15 @@ const docTemplate = `{
                 "next": {
                     "type": "boolean"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "offset": {
                     "type": "integer"
                 },
+                "prev_cursor": {
+                    "type": "string"
+                },
                 "total": {
                     "type": "integer"
                 }
@@ -410,6 +1122,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "\"Bearer \u003cADMIN_TOKEN\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -3,10 +3,22 @@ package api
 import (
 	"log/slog"
 	_ "test-task/docs"
+	"test-task/internal/api/handlers/admin/breakers"
+	"test-task/internal/api/handlers/admin/purge"
+	"test-task/internal/api/handlers/people/batch"
 	"test-task/internal/api/handlers/people/create"
 	deleteHandler "test-task/internal/api/handlers/people/delete"
+	"test-task/internal/api/handlers/people/get"
+	"test-task/internal/api/handlers/people/history"
 	"test-task/internal/api/handlers/people/list"
+	"test-task/internal/api/handlers/people/restore"
+	"test-task/internal/api/handlers/people/review"
+	"test-task/internal/api/handlers/people/search"
 	"test-task/internal/api/handlers/people/update"
+	adminMiddleware "test-task/internal/api/middleware/admin"
+	auditMiddleware "test-task/internal/api/middleware/audit"
+	"test-task/internal/config"
+	"test-task/internal/lib/api/cursor"
 	"test-task/internal/services/enrich"
 	"test-task/internal/storage"
 
@@ -20,19 +32,99 @@ type API struct {
 	storage  storage.Storage
 	log      *slog.Logger
 	Enricher *enrich.Enricher
+	cursor   *cursor.Signer
+	cfg      *config.Config
 }
 
-func New(log *slog.Logger, storage storage.Storage) *API {
+func New(log *slog.Logger, storage storage.Storage, cfg *config.Config) (*API, error) {
+	signer, err := cursor.New(cfg.CursorSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	enricher, err := enrich.New(log, enrich.Config{
+		Providers: cfg.EnrichProviders,
+		Partial:   cfg.EnrichPartial,
+		Localized: cfg.EnrichLocalized,
+		CacheSize: cfg.EnrichCacheSize,
+		CacheTTL:  cfg.EnrichCacheTTL,
+		Breaker: enrich.BreakerConfig{
+			FailureThreshold: cfg.BreakerFailures,
+			OpenTimeout:      cfg.BreakerOpenTimeout,
+			HalfOpenRequests: cfg.BreakerHalfOpenRequests,
+		},
+		RateLimit: enrich.RateLimitConfig{
+			Limit:   cfg.RateLimit,
+			Period:  cfg.RateLimitPeriod,
+			Policy:  enrich.RateLimitPolicy(cfg.RateLimitPolicy),
+			MaxWait: cfg.RateLimitMaxWait,
+		},
+		Batch: enrich.BatchConfig{
+			Window: cfg.BatchWindow,
+			Size:   cfg.BatchSize,
+		},
+		Thresholds: map[enrich.Attribute]enrich.Threshold{
+			enrich.AttributeAge: {MinCount: cfg.AgeMinCount},
+			enrich.AttributeGender: {
+				MinProbability: cfg.GenderMinProbability,
+				MinCount:       cfg.GenderMinCount,
+			},
+			enrich.AttributeNationality: {
+				MinProbability: cfg.NationalityMinProbability,
+				MinCount:       cfg.NationalityMinCount,
+			},
+		},
+		Agify: enrich.ProviderConfig{
+			BaseURL: cfg.AgifyURL,
+			Timeout: cfg.AgifyTimeout,
+			APIKey:  cfg.AgifyAPIKey,
+			Retry: enrich.RetryConfig{
+				MaxAttempts: cfg.AgifyRetryAttempts,
+				BaseDelay:   cfg.AgifyRetryBaseDelay,
+				MaxDelay:    cfg.AgifyRetryMaxDelay,
+			},
+		},
+		Genderize: enrich.ProviderConfig{
+			BaseURL: cfg.GenderizeURL,
+			Timeout: cfg.GenderizeTimeout,
+			APIKey:  cfg.GenderizeAPIKey,
+			Retry: enrich.RetryConfig{
+				MaxAttempts: cfg.GenderizeRetryAttempts,
+				BaseDelay:   cfg.GenderizeRetryBaseDelay,
+				MaxDelay:    cfg.GenderizeRetryMaxDelay,
+			},
+		},
+		Nationalize: enrich.ProviderConfig{
+			BaseURL: cfg.NationalizeURL,
+			Timeout: cfg.NationalizeTimeout,
+			APIKey:  cfg.NationalizeAPIKey,
+			Retry: enrich.RetryConfig{
+				MaxAttempts: cfg.NationalizeRetryAttempts,
+				BaseDelay:   cfg.NationalizeRetryBaseDelay,
+				MaxDelay:    cfg.NationalizeRetryMaxDelay,
+			},
+		},
+	}, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CursorSecret == "" {
+		log.Warn("CURSOR_SECRET is not set. Cursors will be invalid after restart")
+	}
+
 	api := &API{
 		Router:   gin.New(),
 		storage:  storage,
 		log:      log,
-		Enricher: enrich.New(log),
+		Enricher: enricher,
+		cursor:   signer,
+		cfg:      cfg,
 	}
 
 	api.Endpoints()
 
-	return api
+	return api, nil
 }
 
 func (api *API) Endpoints() {
@@ -40,12 +132,24 @@ func (api *API) Endpoints() {
 	v1 := api.Router.Group("api/v1/")
 
 	v1.Use(requestid.New())
+	v1.Use(auditMiddleware.New())
 	v1.Use(gin.Logger())
 
-	v1.GET("/people", list.New(api.log, api.storage)) //ADD SORTING
-	v1.POST("/people", create.New(api.log, api.Enricher, api.storage))
+	v1.GET("/people", list.New(api.log, api.storage, api.cursor))
+	v1.GET("/people/search", search.New(api.log, api.storage))
+	v1.GET("/people/review", review.New(api.log, api.storage))
+	v1.GET("/people/:id", get.New(api.log, api.storage))
+	v1.POST("/people", create.New(api.log, api.Enricher, api.storage, api.cfg.EnrichAsync))
+	v1.POST("/people/batch", batch.New(api.log, api.Enricher, api.storage, api.cfg.BatchMaxSize, api.cfg.BatchEnrichConcurrency))
 	v1.PATCH("/people/:id", update.New(api.log, api.storage, api.storage))
 	v1.DELETE("/people/:id", deleteHandler.New(api.log, api.storage))
+	v1.POST("/people/:id/restore", restore.New(api.log, api.storage))
+	v1.GET("/people/:id/history", history.New(api.log, api.storage))
+
+	admin := v1.Group("/admin", adminMiddleware.New(api.log, api.cfg.AdminToken))
+
+	admin.POST("/people/purge", purge.New(api.log, api.storage, api.cfg.PurgeRetention))
+	admin.GET("/enrichment/breakers", breakers.New(api.log, api.Enricher))
 
 	v1.GET("/swagger/*any", gin.WrapH(httpSwagger.Handler()))
 
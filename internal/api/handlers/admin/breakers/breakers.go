@@ -0,0 +1,49 @@
+package breakers
+
+import (
+	"log/slog"
+	"net/http"
+	"test-task/internal/lib/api/response"
+	"test-task/internal/services/enrich"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type Response struct {
+	Resp     response.Response      `json:"response"`
+	Breakers []enrich.BreakerStatus `json:"breakers"`
+}
+
+type BreakerStater interface {
+	Breakers() []enrich.BreakerStatus
+}
+
+// Breakers godoc
+//
+// @Summary 	Enrichment circuit breakers
+// @Description State of circuit breaker of every enrichment provider: closed, open (calls fail fast) or half-open (trial calls).
+// @Description Empty if breakers are disabled (ENRICH_BREAKER_FAILURES=0)
+// @Tags 		admin
+// @ID 			breakers
+// @Produce 	json
+// @Security	AdminToken
+// @Success 200 {object} Response "OK"
+// @Failure 	401 {object} response.Response "Unauthorized"
+// @Failure 	403 {object} response.Response "Admin API is disabled"
+// @Router 		/admin/enrichment/breakers [get]
+func New(log *slog.Logger, Stater BreakerStater) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		statuses := Stater.Breakers()
+
+		logHandler.Debug("breakers state", "breakers", statuses)
+
+		c.JSON(http.StatusOK, Response{Resp: response.OK(), Breakers: statuses})
+
+	}
+}
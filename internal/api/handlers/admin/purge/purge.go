@@ -0,0 +1,61 @@
+package purge
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"test-task/internal/lib/api/response"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type Response struct {
+	Resp   response.Response `json:"response"`
+	Purged int64             `json:"purged"`
+}
+
+type PersonPurger interface {
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// Purge godoc
+//
+// @Summary 	Purge deleted people
+// @Description Remove forever people soft deleted longer than retention (PURGE_RETENTION)
+// @Tags 		admin
+// @ID 			purge
+// @Produce 	json
+// @Security	AdminToken
+// @Success 200 {object} Response "OK - number of purged people"
+// @Failure 	401 {object} response.Response "Unauthorized"
+// @Failure 	403 {object} response.Response "Admin API is disabled"
+// @Failure 	500 {object} response.Response "Internal error"
+// @Router 		/admin/people/purge [post]
+func New(log *slog.Logger, Purger PersonPurger, retention time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		deletedBefore := time.Now().Add(-retention)
+
+		purged, err := Purger.Purge(ctx, deletedBefore)
+		if err != nil {
+			logHandler.Error("can't purge people", "err", err.Error())
+
+			c.JSON(http.StatusInternalServerError, response.Error("Internal server error"))
+
+			return
+		}
+
+		logHandler.Info("deleted people purged", "count", purged, "deletedBefore", deletedBefore)
+
+		c.JSON(http.StatusOK, Response{Resp: response.OK(), Purged: purged})
+
+	}
+}
@@ -0,0 +1,192 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"test-task/internal/api/handlers/people/create"
+	"test-task/internal/domain/models"
+	"test-task/internal/lib/api/response"
+	"test-task/internal/services/enrich"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ItemResult - result of one person of batch, Index is position in request array
+type ItemResult struct {
+	Index int    `json:"index"`
+	ID    int64  `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type Response struct {
+	Resp   response.Response `json:"response"`
+	Saved  int               `json:"saved"`
+	Failed int               `json:"failed"`
+	Items  []ItemResult      `json:"items"`
+}
+
+type BatchSaver interface {
+	SaveBatch(ctx context.Context, entities []*models.Person) ([]int64, error)
+}
+
+// BulkEnricher enriches many people at once with name[] requests, errors are in order of people
+type BulkEnricher interface {
+	EnrichAll(ctx context.Context, people []*models.Person) []error
+}
+
+// Batch godoc
+//
+// @Summary 	Create many users
+// @Description Creating, enriching and saving array of users at once.
+// @Description Invalid or not enriched items are reported in items and don't stop others.
+// @Description Names are enriched with name[] requests of up to 10 names
+// @Tags 		people
+// @ID 			batch
+// @Accept 		json
+// @Produce 	json
+// @Param		input body []create.Request true "Array of person basic info"
+// @Success 200 {object} Response "OK - per item ids and errors"
+// @Failure 	400 {object} response.Response "Invalid input"
+// @Failure 	413 {object} response.Response "Too many items"
+// @Failure 	500 {object} response.Response "Internal error"
+// @Router 		/people/batch [post]
+// concurrency - number of name[] requests enriched at once, people are enriched by chunks of them
+func New(log *slog.Logger, Enricher BulkEnricher, Saver BatchSaver, maxSize int, concurrency int) gin.HandlerFunc {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	chunkSize := concurrency * enrich.MaxBatchSize
+
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		var req []create.Request
+
+		if err := c.BindJSON(&req); err != nil {
+			logHandler.Error("can't decode request body", "err", err.Error())
+
+			c.JSON(http.StatusBadRequest, response.Error("failed to decode request body"))
+
+			return
+		}
+
+		if len(req) == 0 {
+			logHandler.Error("empty batch")
+
+			c.JSON(http.StatusBadRequest, response.Error("empty batch"))
+
+			return
+		}
+
+		if len(req) > maxSize {
+			logHandler.Error("batch is too big", "size", len(req), "max", maxSize)
+
+			c.JSON(http.StatusRequestEntityTooLarge, response.Error(fmt.Sprintf("batch can't be bigger than %d", maxSize)))
+
+			return
+		}
+
+		items := make([]ItemResult, len(req))
+		persons := make([]*models.Person, len(req))
+
+		validate := validator.New()
+
+		indexes := make([]int, 0, len(req))
+		people := make([]*models.Person, 0, len(req))
+
+		for i, item := range req {
+			items[i].Index = i
+
+			if err := validate.Struct(item); err != nil {
+				var validatorErr validator.ValidationErrors
+				if errors.As(err, &validatorErr) {
+					items[i].Error = response.ValidationError(validatorErr).Error
+				} else {
+					items[i].Error = err.Error()
+				}
+
+				continue
+			}
+
+			indexes = append(indexes, i)
+			people = append(people, &models.Person{
+				Name:        item.Name,
+				Surname:     item.Surname,
+				Patronymic:  item.Patronymic,
+				CountryHint: item.Country,
+			})
+		}
+
+		for start := 0; start < len(people); start += chunkSize {
+			chunk := people[start:min(start+chunkSize, len(people))]
+
+			for j, err := range Enricher.EnrichAll(ctx, chunk) {
+				i := indexes[start+j]
+
+				if err != nil {
+					logHandler.Error("can't enrich person", "index", i, "err", err.Error())
+
+					items[i].Error = "can't enrich person"
+
+					continue
+				}
+
+				person := chunk[j]
+
+				if person.EnrichmentFailed() {
+					person.EnrichmentStatus = models.EnrichmentPending
+				}
+
+				if person.Patronymic == "" || person.Patronymic == " " {
+					person.Patronymic = "N/A"
+				}
+
+				persons[i] = person
+			}
+		}
+
+		toSave := make([]*models.Person, 0, len(persons))
+		for _, person := range persons {
+			if person != nil {
+				toSave = append(toSave, person)
+			}
+		}
+
+		_, err := Saver.SaveBatch(ctx, toSave)
+		if err != nil {
+			logHandler.Error("can't save batch", "err", err.Error())
+
+			c.JSON(http.StatusInternalServerError, response.Error("Internal server error"))
+
+			return
+		}
+
+		resp := Response{Resp: response.OK(), Items: items}
+
+		for i, person := range persons {
+			if person == nil {
+				resp.Failed++
+				continue
+			}
+
+			items[i].ID = person.ID
+			resp.Saved++
+		}
+
+		logHandler.Info("batch saved", "saved", resp.Saved, "failed", resp.Failed)
+
+		c.JSON(http.StatusOK, resp)
+
+	}
+}
@@ -0,0 +1,96 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"test-task/internal/api/handlers/people/create"
+	"test-task/internal/domain/models"
+	"test-task/internal/services/enrich"
+	"test-task/internal/services/enrich/enrichstub"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubSaver struct{}
+
+func (stubSaver) SaveBatch(ctx context.Context, entities []*models.Person) ([]int64, error) {
+	ids := make([]int64, len(entities))
+	for i, entity := range entities {
+		entity.ID = int64(i + 1)
+		ids[i] = entity.ID
+	}
+
+	return ids, nil
+}
+
+func TestNew_BatchedProviderCalls(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name         string
+		people       int
+		wantRequests int
+	}{
+		{name: "one request", people: 7, wantRequests: 1},
+		{name: "request per ten names", people: 95, wantRequests: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := enrichstub.Start(enrichstub.Options{})
+			defer srv.Close()
+
+			// config defaults: localized, batching of single lookups is off, 8 requests at once
+			enricher, err := enrich.New(log, enrich.Config{
+				Localized:   true,
+				Providers:   []string{enrich.AgifyName, enrich.GenderizeName, enrich.NationalizeName},
+				Agify:       enrich.ProviderConfig{BaseURL: srv.AgifyURL(), Timeout: time.Second},
+				Genderize:   enrich.ProviderConfig{BaseURL: srv.GenderizeURL(), Timeout: time.Second},
+				Nationalize: enrich.ProviderConfig{BaseURL: srv.NationalizeURL(), Timeout: time.Second},
+			}, nil)
+			if err != nil {
+				t.Fatalf("enrich.New() error = %v", err)
+			}
+
+			router := gin.New()
+			router.POST("/people/batch", New(log, enricher, stubSaver{}, 1000, 8))
+
+			req := make([]create.Request, tt.people)
+			for i := range req {
+				req[i] = create.Request{Name: fmt.Sprintf("Name%d", i), Surname: "Petrov", Country: "RU"}
+			}
+
+			body, _ := json.Marshal(req)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/people/batch", bytes.NewReader(body)))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var resp Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("can't decode response: %v", err)
+			}
+			if resp.Saved != tt.people {
+				t.Errorf("saved = %d, want %d", resp.Saved, tt.people)
+			}
+
+			for _, path := range []string{enrichstub.AgifyPath, enrichstub.GenderizePath, enrichstub.NationalizePath} {
+				if n := srv.Requests(path); n != tt.wantRequests {
+					t.Errorf("Requests(%s) = %d, want %d", path, n, tt.wantRequests)
+				}
+			}
+		})
+	}
+}
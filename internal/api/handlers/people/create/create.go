@@ -2,9 +2,11 @@ package create
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"test-task/internal/domain/models"
+	"test-task/internal/lib/api/etag"
 	"test-task/internal/lib/api/response"
 
 	"github.com/gin-contrib/requestid"
@@ -19,11 +21,14 @@ type Request struct {
 	// required
 	Patronymic string `json:"patronymic,omitempty" validate:"omitempty,min=2,max=50" example:"Petrovich"`
 	// omitempty
+	Country string `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2" example:"RU"`
+	// omitempty. ISO 3166-1 alpha-2 code to localize age and gender estimates
 }
 
 type Response struct {
-	Resp response.Response `json:"response"`
-	ID   int64             `json:"id,omitempty"`
+	Resp   response.Response `json:"response"`
+	ID     int64             `json:"id,omitempty"`
+	Person *models.Person    `json:"person,omitempty"`
 }
 
 type PersonSaver interface {
@@ -37,17 +42,23 @@ type IEnricher interface {
 // Create godoc
 //
 // @Summary 	Create new user
-// @Description Creating, enriching and saving new user
+// @Description Creating, enriching and saving new user.
+// @Description In async mode (ENRICH_ASYNC) person is saved with pending EnrichmentStatus and enriched in background,
+// @Description status is done or failed when it's finished.
+// @Description With ENRICH_PARTIAL person is saved if some providers failed: their attributes are null with failed status in enrichment
+// @Description and EnrichmentStatus is pending till they are enriched in background.
+// @Description Age and gender are estimated for country if it's given, otherwise for nationality found first (ENRICH_LOCALIZED)
 // @Tags 		people
 // @ID 			create
 // @Accept 		json
 // @Produce 	json
 // @Param		input body Request true "Person basic info"
 // @Success 200 {object} Response "OK"
+// @Success 202 {object} Response "Accepted - person is saved, enrichment is pending"
 // @Failure 	400 "Invalid input"
 // @Failure 	500 "Internal error"
 // @Router 		/people [post]
-func New(log *slog.Logger, Enricher IEnricher, Saver PersonSaver) gin.HandlerFunc {
+func New(log *slog.Logger, Enricher IEnricher, Saver PersonSaver, async bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		ctx := c.Request.Context()
@@ -77,18 +88,34 @@ func New(log *slog.Logger, Enricher IEnricher, Saver PersonSaver) gin.HandlerFun
 		}
 
 		person := &models.Person{
-			Name:       req.Name,
-			Surname:    req.Surname,
-			Patronymic: req.Patronymic,
+			Name:             req.Name,
+			Surname:          req.Surname,
+			Patronymic:       req.Patronymic,
+			CountryHint:      req.Country,
+			EnrichmentStatus: models.EnrichmentDone,
 		}
 
-		person, err := Enricher.Enrich(ctx, person)
-		if err != nil {
-			logHandler.Error("can't enrich person", "err", err.Error())
+		status := http.StatusOK
 
-			c.JSON(http.StatusInternalServerError, response.Error("Internal server error"))
+		if async {
+			person.EnrichmentStatus = models.EnrichmentPending
+			status = http.StatusAccepted
+		} else {
+			var err error
 
-			return
+			person, err = Enricher.Enrich(ctx, person)
+			if err != nil {
+				logHandler.Error("can't enrich person", "err", err.Error())
+
+				c.JSON(http.StatusInternalServerError, response.Error("Internal server error"))
+
+				return
+			}
+
+			// ENRICH_PARTIAL: недостающие атрибуты дообогатят воркеры
+			if person.EnrichmentFailed() {
+				person.EnrichmentStatus = models.EnrichmentPending
+			}
 		}
 
 		if person.Patronymic == "" || person.Patronymic == " " {
@@ -106,7 +133,13 @@ func New(log *slog.Logger, Enricher IEnricher, Saver PersonSaver) gin.HandlerFun
 
 		logHandler.Info("person saved", "Person", person, "id", id)
 
-		c.JSON(http.StatusOK, Response{Resp: response.OK(), ID: id})
+		c.Header("ETag", etag.Format(person.Version))
+
+		if async {
+			c.Header("Location", fmt.Sprintf("/api/v1/people/%d", id))
+		}
+
+		c.JSON(status, Response{Resp: response.OK(), ID: id, Person: person})
 
 	}
 }
@@ -20,7 +20,7 @@ type PersonDeleter interface {
 // Delete godoc
 //
 // @Summary 	Delete
-// @Description Delete the user by id
+// @Description Soft delete the user by id. Person can be restored until purge
 // @Tags 		people
 // @ID 			create
 // @Accept 		json
@@ -47,6 +47,8 @@ func New(log *slog.Logger, Deleter PersonDeleter) gin.HandlerFunc {
 			logHandler.Error("can't param ID make int64")
 
 			c.JSON(http.StatusBadRequest, response.Error("Invalid ID"))
+
+			return
 		}
 
 		err = Deleter.Delete(ctx, int64(id))
@@ -55,6 +57,8 @@ func New(log *slog.Logger, Deleter PersonDeleter) gin.HandlerFunc {
 				logHandler.Error("can't delete person", "err", err.Error())
 
 				c.JSON(http.StatusNoContent, nil)
+
+				return
 			}
 			logHandler.Error("can't delete person", "err", err.Error())
 
@@ -0,0 +1,84 @@
+package get
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"test-task/internal/domain/models"
+	"test-task/internal/lib/api/etag"
+	"test-task/internal/lib/api/response"
+	"test-task/internal/storage"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type Response struct {
+	Resp   response.Response `json:"response"`
+	Person *models.Person    `json:"person,omitempty"`
+}
+
+type PersonProvider interface {
+	FindByID(ctx context.Context, id int64) (*models.Person, error)
+}
+
+// Get godoc
+//
+// @Summary 	Get person
+// @Description Get one person by id
+// @Tags 		people
+// @ID 			get
+// @Produce 	json
+// @Param		id path int true "Person ID"
+// @Success 200 {object} Response "OK"
+// @Header  200 {string} ETag "person version, use it in If-Match of PATCH"
+// @Failure 	400 {object} response.Response "Invalid id"
+// @Failure 	404 {object} response.Response "Person not found"
+// @Failure 	500 {object} response.Response "Internal error"
+// @Router 		/people/{id} [get]
+func New(log *slog.Logger, Provider PersonProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		paramId := c.Param("id")
+
+		id, err := strconv.ParseInt(paramId, 10, 64)
+		if err != nil {
+			logHandler.Error("can't param ID make int64", "id", paramId)
+
+			c.JSON(http.StatusBadRequest, response.Error("Invalid ID"))
+
+			return
+		}
+
+		person, err := Provider.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrIDNotFound) {
+				logHandler.Error("personID not found", "err", err.Error())
+
+				c.JSON(http.StatusNotFound, response.Error("Person not found"))
+
+				return
+			}
+			logHandler.Error("can't find person", "err", err.Error())
+
+			c.JSON(http.StatusInternalServerError, response.Error("Internal server error"))
+
+			return
+		}
+
+		logHandler.Debug("person found", "id", id)
+
+		c.Header("ETag", etag.Format(person.Version))
+
+		c.JSON(http.StatusOK, Response{Resp: response.OK(), Person: person})
+
+	}
+}
@@ -0,0 +1,88 @@
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"test-task/internal/domain/models"
+	"test-task/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubProvider map[int64]*models.Person
+
+func (p stubProvider) FindByID(ctx context.Context, id int64) (*models.Person, error) {
+	person, ok := p[id]
+	if !ok {
+		return nil, storage.ErrIDNotFound
+	}
+
+	return person, nil
+}
+
+func TestNew(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	provider := stubProvider{
+		1: {
+			ID: 1, Name: "Dmitriy", Surname: "Ushakov", Age: 42, Gender: "male", Nationality: "RU",
+			EnrichmentStatus: models.EnrichmentDone, CreatedAt: time.Now(), UpdatedAt: time.Now(), Version: 3,
+		},
+	}
+
+	router := gin.New()
+	router.GET("/people/:id", New(log, provider))
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantKeys   []string
+	}{
+		{
+			name:       "found",
+			path:       "/people/1",
+			wantStatus: http.StatusOK,
+			wantKeys: []string{
+				"id", "name", "surname", "patronymic", "age", "gender", "nationality",
+				"enrichment_status", "created_at", "updated_at", "version",
+			},
+		},
+		{name: "not found", path: "/people/2", wantStatus: http.StatusNotFound},
+		{name: "invalid id", path: "/people/abc", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			var body struct {
+				Person map[string]any `json:"person"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("can't decode response: %v", err)
+			}
+
+			for _, key := range tt.wantKeys {
+				if _, ok := body.Person[key]; !ok {
+					t.Errorf("person has no %q key: %v", key, body.Person)
+				}
+			}
+			if tt.wantStatus == http.StatusOK && w.Header().Get("ETag") != `"3"` {
+				t.Errorf("ETag = %s, want %s", w.Header().Get("ETag"), `"3"`)
+			}
+		})
+	}
+}
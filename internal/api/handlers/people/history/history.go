@@ -0,0 +1,79 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"test-task/internal/domain/models"
+	"test-task/internal/lib/api/response"
+	"test-task/internal/storage"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type Response struct {
+	Resp response.Response      `json:"response"`
+	Data []*models.PersonChange `json:"data"`
+}
+
+type HistoryProvider interface {
+	History(ctx context.Context, personID int64) ([]*models.PersonChange, error)
+}
+
+// History godoc
+//
+// @Summary 	Person history
+// @Description All changes of person from the oldest: operation, old and new values, request ID, actor (unauthenticated X-Actor header as sent by client) and time.
+// @Description Available for deleted and purged people too, purge is the last record
+// @Tags 		people
+// @ID 			history
+// @Produce 	json
+// @Param		id path int true "Person ID"
+// @Success 200 {object} Response "OK"
+// @Failure 	400 {object} response.Response "Invalid id"
+// @Failure 	404 {object} response.Response "No history for person"
+// @Failure 	500 {object} response.Response "Internal error"
+// @Router 		/people/{id}/history [get]
+func New(log *slog.Logger, Provider HistoryProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		paramId := c.Param("id")
+
+		id, err := strconv.ParseInt(paramId, 10, 64)
+		if err != nil {
+			logHandler.Error("can't param ID make int64", "id", paramId)
+
+			c.JSON(http.StatusBadRequest, response.Error("Invalid ID"))
+
+			return
+		}
+
+		changes, err := Provider.History(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrIDNotFound) {
+				logHandler.Error("no history for person", "err", err.Error())
+
+				c.JSON(http.StatusNotFound, response.Error("Person not found"))
+
+				return
+			}
+			logHandler.Error("can't get person history", "err", err.Error())
+
+			c.JSON(http.StatusInternalServerError, response.Error("Internal server error"))
+
+			return
+		}
+
+		c.JSON(http.StatusOK, Response{Resp: response.OK(), Data: changes})
+
+	}
+}
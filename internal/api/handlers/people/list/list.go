@@ -31,6 +31,18 @@ type Response struct {
 
 type Pager interface {
 	FilteredPages(ctx context.Context, offset int, limit int, options *filters.Options) ([]*models.Person, int, error)
+	KeysetPages(ctx context.Context, keyset *filters.Keyset, limit int, options *filters.Options) ([]*models.Person, *filters.Keyset, *filters.Keyset, error)
+}
+
+type CursorSigner interface {
+	Encode(payload any) (string, error)
+	Decode(cursor string, payload any) error
+}
+
+// cursorPayload - content of opaque cursor. Cursor is valid only for the sort it was issued for
+type cursorPayload struct {
+	Sort   string          `json:"s"`
+	Keyset *filters.Keyset `json:"k"`
 }
 
 // Listgodoc
@@ -44,17 +56,27 @@ type Pager interface {
 // @Param        name			query  string	false  "person filter by name"			example(oleg)
 // @Param        surname		query  string	false  "person filter by surname"		example(invanov)
 // @Param        patronymic		query  string	false  "person filter by patronymic"	example(petrovich)
+// @Param        name_prefix			query  string	false  "name starts with, case-insensitive"			example(ol)
+// @Param        name_contains			query  string	false  "name contains, case-insensitive"			example(le)
+// @Param        surname_prefix			query  string	false  "surname starts with, case-insensitive"		example(iva)
+// @Param        surname_contains		query  string	false  "surname contains, case-insensitive"			example(petr)
+// @Param        patronymic_prefix		query  string	false  "patronymic starts with, case-insensitive"	example(petr)
+// @Param        patronymic_contains	query  string	false  "patronymic contains, case-insensitive"		example(ovich)
+// @Param        ignore_case			query  bool		false  "exact name, surname and patronymic filters ignore case" example(true)
 // @Param        age    		query  int		false  "person filter by exact age" 	example(32)
 // @Param        minage			query  int		false  "person filter by min age" 		example(10)
 // @Param        maxage			query  int		false  "person filter by max age" 		example(35)
 // @Param        gender 		query  string	false  "person filter by gender" 		example(male)
 // @Param        nationality	query  string	false  "person filter by nationality" 	example(RU)
+// @Param        nationality_probability	query  number	false  "with nationality: also match people having it as nationalize candidate with probability not less" example(0.1)
+// @Param        cursor		query  string	false  "cursor from meta next_cursor/prev_cursor. Switches to cursor pagination, page is ignored. Empty value - first page"
+// @Param        sort			query  string	false  "comma separated sort fields, '-' prefix for descending order. Allowed: id, name, surname, patronymic, age, gender, nationality, created_at, updated_at" example(surname,-age,created_at)
 // @Success      200  {object}  Response
 // @Failure      400  {object}  response.Response
 // @Failure      404  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /people [get]
-func New(log *slog.Logger, Pager Pager) gin.HandlerFunc {
+func New(log *slog.Logger, Pager Pager, Signer CursorSigner) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		ctx := c.Request.Context()
@@ -65,11 +87,24 @@ func New(log *slog.Logger, Pager Pager) gin.HandlerFunc {
 
 		filterOp, err := setFilterQueries(logHandler, c)
 		if err != nil {
-			if errors.Is(err, ErrConvertParam) {
-				logHandler.Error(ErrConvertParam.Error(), "err", err)
+			if errors.Is(err, filters.ErrInvalidSort) {
+				logHandler.Error(filters.ErrInvalidSort.Error(), "err", err)
+
+				c.JSON(http.StatusBadRequest, response.Error(err.Error()))
 
-				c.JSON(http.StatusBadRequest, response.Error("Invalid Parameters"))
+				return
 			}
+			logHandler.Error(ErrConvertParam.Error(), "err", err)
+
+			c.JSON(http.StatusBadRequest, response.Error("Invalid Parameters"))
+
+			return
+		}
+
+		if cursorQuery, ok := c.GetQuery("cursor"); ok {
+			keysetPage(logHandler, c, Pager, Signer, filterOp, cursorQuery)
+
+			return
 		}
 
 		var pag types.Pagination
@@ -107,6 +142,12 @@ func New(log *slog.Logger, Pager Pager) gin.HandlerFunc {
 
 		users, count, err := Pager.FilteredPages(ctx, offset, pag.Limit, filterOp)
 		if err != nil {
+			if errors.Is(err, filters.ErrInvalidSort) {
+				logHandler.Error("invalid sort", "err", err.Error())
+
+				c.JSON(http.StatusBadRequest, response.Error(err.Error()))
+				return
+			}
 			logHandler.Error("can't get list of persons", "err", err.Error())
 
 			c.JSON(http.StatusInternalServerError, response.Error("Internal Server Error"))
@@ -125,10 +166,96 @@ func New(log *slog.Logger, Pager Pager) gin.HandlerFunc {
 	}
 }
 
+// keysetPage - cursor pagination mode. Doesn't count total
+func keysetPage(log *slog.Logger, c *gin.Context, Pager Pager, Signer CursorSigner, filterOp *filters.Options, cursorQuery string) {
+
+	limitQurey := c.DefaultQuery("limit", defaultLimit)
+
+	limit, err := strconv.Atoi(limitQurey)
+	if err != nil || limit < 1 {
+		log.Error(ErrConvertParam.Error(), "param", "limit", "query", limitQurey)
+
+		c.JSON(http.StatusBadRequest, response.Error(fmt.Sprintf("Invalid parameter:%s", limitQurey)))
+
+		return
+	}
+
+	sort := filters.FormatSort(filterOp.Sort)
+
+	var keyset *filters.Keyset
+
+	if cursorQuery != "" {
+		var payload cursorPayload
+
+		if err := Signer.Decode(cursorQuery, &payload); err != nil {
+			log.Error("can't decode cursor", "err", err.Error())
+
+			c.JSON(http.StatusBadRequest, response.Error("Invalid cursor"))
+
+			return
+		}
+
+		if payload.Sort != sort || payload.Keyset == nil {
+			log.Error("cursor was issued for another sort", "cursor sort", payload.Sort, "sort", sort)
+
+			c.JSON(http.StatusBadRequest, response.Error("Invalid cursor: sort changed"))
+
+			return
+		}
+
+		keyset = payload.Keyset
+	}
+
+	log.Debug("Keyset pagination query", "limit", limit, "cursor", cursorQuery)
+
+	users, next, prev, err := Pager.KeysetPages(c.Request.Context(), keyset, limit, filterOp)
+	if err != nil {
+		if errors.Is(err, filters.ErrInvalidSort) || errors.Is(err, filters.ErrInvalidKeyset) {
+			log.Error("invalid cursor pagination params", "err", err.Error())
+
+			c.JSON(http.StatusBadRequest, response.Error(err.Error()))
+			return
+		}
+		log.Error("can't get list of persons", "err", err.Error())
+
+		c.JSON(http.StatusInternalServerError, response.Error("Internal Server Error"))
+		return
+	}
+
+	meta := &types.Meta{
+		Limit: limit,
+		Next:  next != nil,
+	}
+
+	if next != nil {
+		meta.NextCursor, err = Signer.Encode(cursorPayload{Sort: sort, Keyset: next})
+	}
+	if err == nil && prev != nil {
+		meta.PrevCursor, err = Signer.Encode(cursorPayload{Sort: sort, Keyset: prev})
+	}
+	if err != nil {
+		log.Error("can't encode cursor", "err", err.Error())
+
+		c.JSON(http.StatusInternalServerError, response.Error("Internal Server Error"))
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{Resp: response.OK(), Data: users, Meta: meta})
+}
+
 func setFilterQueries(log *slog.Logger, c *gin.Context) (*filters.Options, error) {
 
 	var op filters.Options
 
+	sort, err := filters.ParseSort(c.Query("sort"))
+	if err != nil {
+		log.Error(filters.ErrInvalidSort.Error(), "param", "sort", "query", c.Query("sort"))
+
+		return nil, err
+	}
+
+	op.Sort = sort
+
 	name := c.Query("name")
 	if name != "" {
 		op.Name = &name
@@ -144,6 +271,32 @@ func setFilterQueries(log *slog.Logger, c *gin.Context) (*filters.Options, error
 		op.Patronymic = &patronymic
 	}
 
+	for param, field := range map[string]**string{
+		"name_prefix":         &op.NamePrefix,
+		"name_contains":       &op.NameContains,
+		"surname_prefix":      &op.SurnamePrefix,
+		"surname_contains":    &op.SurnameContains,
+		"patronymic_prefix":   &op.PatronymicPrefix,
+		"patronymic_contains": &op.PatronymicContains,
+	} {
+		value := c.Query(param)
+		if value != "" {
+			*field = &value
+		}
+	}
+
+	ignoreCase := c.Query("ignore_case")
+	if ignoreCase != "" {
+		ignoreCaseBool, err := strconv.ParseBool(ignoreCase)
+		if err != nil {
+			log.Error(ErrConvertParam.Error(), "param", "ignore_case", "query", ignoreCase)
+
+			return nil, fmt.Errorf("%w:%s", ErrConvertParam, ignoreCase)
+		}
+
+		op.IgnoreCase = ignoreCaseBool
+	}
+
 	gender := c.Query("gender")
 	if gender != "" {
 		op.Gender = &gender
@@ -154,6 +307,18 @@ func setFilterQueries(log *slog.Logger, c *gin.Context) (*filters.Options, error
 		op.Nationality = &nationality
 	}
 
+	nationalityProbability := c.Query("nationality_probability")
+	if nationalityProbability != "" {
+		probability, err := strconv.ParseFloat(nationalityProbability, 64)
+		if err != nil || probability < 0 || probability > 1 {
+			log.Error(ErrConvertParam.Error(), "param", "nationality_probability", "query", nationalityProbability)
+
+			return nil, fmt.Errorf("%w:%s", ErrConvertParam, nationalityProbability)
+		}
+
+		op.NationalityProbability = &probability
+	}
+
 	age := c.Query("age") //точный возраст
 	if age != "" {
 		ageInt, err := strconv.Atoi(age)
@@ -0,0 +1,68 @@
+package list
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"test-task/internal/domain/filters"
+	"test-task/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubPager struct {
+	people []*models.Person
+}
+
+func (p stubPager) FilteredPages(ctx context.Context, offset int, limit int, options *filters.Options) ([]*models.Person, int, error) {
+	return p.people, len(p.people), nil
+}
+
+func (p stubPager) KeysetPages(ctx context.Context, keyset *filters.Keyset, limit int, options *filters.Options) ([]*models.Person, *filters.Keyset, *filters.Keyset, error) {
+	return p.people, nil, nil, nil
+}
+
+func TestNew_ResponseKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	person := &models.Person{
+		ID: 1, Name: "Dmitriy", Surname: "Ushakov", Age: 42, Gender: "male", Nationality: "RU",
+		EnrichmentStatus: models.EnrichmentDone, CreatedAt: time.Now(), UpdatedAt: time.Now(), Version: 1,
+	}
+
+	router := gin.New()
+	router.GET("/people", New(log, stubPager{people: []*models.Person{person}}, nil))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/people", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("can't decode response: %v", err)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("len(data) = %d, want 1", len(body.Data))
+	}
+
+	for _, key := range []string{
+		"id", "name", "surname", "patronymic", "age", "gender", "nationality",
+		"enrichment_status", "created_at", "updated_at", "version",
+	} {
+		if _, ok := body.Data[0][key]; !ok {
+			t.Errorf("person has no %q key: %v", key, body.Data[0])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"test-task/internal/lib/api/response"
+	"test-task/internal/storage"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type PersonRestorer interface {
+	Restore(ctx context.Context, id int64) error
+}
+
+// Restore godoc
+//
+// @Summary 	Restore
+// @Description Restore soft deleted person by id
+// @Description Delete and restore change person version, ETags issued before delete don't match anymore
+// @Tags 		people
+// @ID 			restore
+// @Produce 	json
+// @Param		id path int true "Person ID"
+// @Success 200 {object} response.Response "OK"
+// @Failure 	400 {object} response.Response "Invalid id"
+// @Failure 	404 {object} response.Response "Deleted person not found"
+// @Failure 	500 {object} response.Response "Internal error"
+// @Router 		/people/{id}/restore [post]
+func New(log *slog.Logger, Restorer PersonRestorer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		paramId := c.Param("id")
+
+		id, err := strconv.ParseInt(paramId, 10, 64)
+		if err != nil {
+			logHandler.Error("can't param ID make int64", "id", paramId)
+
+			c.JSON(http.StatusBadRequest, response.Error("Invalid ID"))
+
+			return
+		}
+
+		err = Restorer.Restore(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrIDNotFound) {
+				logHandler.Error("deleted person not found", "err", err.Error())
+
+				c.JSON(http.StatusNotFound, response.Error("Deleted person not found"))
+
+				return
+			}
+			logHandler.Error("can't restore person", "err", err.Error())
+
+			c.JSON(http.StatusInternalServerError, response.Error("Internal server error"))
+
+			return
+		}
+
+		logHandler.Info("person restored", "ID", id)
+
+		c.JSON(http.StatusOK, response.OK())
+
+	}
+}
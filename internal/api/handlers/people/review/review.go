@@ -0,0 +1,91 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"test-task/internal/api/handlers/people/list"
+	"test-task/internal/api/types"
+	"test-task/internal/domain/models"
+	"test-task/internal/lib/api/response"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLimit = "10"
+	defaultPage  = "1"
+)
+
+type Reviewer interface {
+	ReviewPages(ctx context.Context, offset int, limit int) ([]*models.Person, int, error)
+}
+
+// Review godoc
+// @Summary      People to review
+// @Description  people with enriched values below confidence threshold (ENRICH_*_MIN_*). Such attributes are empty, enrichment keeps rejected value with its probability and count under unknown status
+// @Description  Person leaves the queue when all unknown attributes are set by PATCH /people/{id}
+// @Tags         people
+// @Produce      json
+// @Param        page			query  int		false  "num of page"				example(1)			default:"1"
+// @Param        limit			query  int		false  "limit wrties on page"		example(3)			default:"10"
+// @Success      200  {object}  list.Response
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /people/review [get]
+func New(log *slog.Logger, Reviewer Reviewer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		var pag types.Pagination
+		var err error
+
+		pageQuery := c.DefaultQuery("page", defaultPage)
+
+		pag.Page, err = strconv.Atoi(pageQuery)
+		if err != nil || pag.Page < 1 {
+			logHandler.Error(list.ErrConvertParam.Error(), "param", "page", "query", pageQuery)
+
+			c.JSON(http.StatusBadRequest, response.Error(fmt.Sprintf("Invalid parameter:%s", pageQuery)))
+
+			return
+		}
+
+		limitQurey := c.DefaultQuery("limit", defaultLimit)
+
+		pag.Limit, err = strconv.Atoi(limitQurey)
+		if err != nil || pag.Limit < 1 {
+			logHandler.Error(list.ErrConvertParam.Error(), "param", "limit", "query", limitQurey)
+
+			c.JSON(http.StatusBadRequest, response.Error(fmt.Sprintf("Invalid parameter:%s", limitQurey)))
+
+			return
+		}
+
+		users, count, err := Reviewer.ReviewPages(ctx, pag.Offset(), pag.Limit)
+		if err != nil {
+			logHandler.Error("can't get people to review", "err", err.Error())
+
+			c.JSON(http.StatusInternalServerError, response.Error("Internal Server Error"))
+			return
+		}
+
+		meta := &types.Meta{
+			Total:  count,
+			Limit:  pag.Limit,
+			Offset: pag.Offset(),
+			Next:   (pag.Offset() + pag.Limit) < count,
+		}
+
+		c.JSON(http.StatusOK, list.Response{Resp: response.OK(), Data: users, Meta: meta})
+
+	}
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"test-task/internal/api/handlers/people/list"
+	"test-task/internal/api/types"
+	"test-task/internal/domain/models"
+	"test-task/internal/lib/api/response"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLimit = "10"
+	defaultPage  = "1"
+)
+
+type Searcher interface {
+	Search(ctx context.Context, text string, offset int, limit int) ([]*models.Person, int, error)
+}
+
+// Search godoc
+// @Summary      Search people
+// @Description  free text search by name, surname and patronymic in any order. Every word matches as prefix. Results are ordered by relevance
+// @Tags         people
+// @Produce      json
+// @Param        q				query  string	true   "search text"				example(Ivanov Petr)
+// @Param        page			query  int		false  "num of page"				example(1)			default:"1"
+// @Param        limit			query  int		false  "limit wrties on page"		example(3)			default:"10"
+// @Success      200  {object}  list.Response
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /people/search [get]
+func New(log *slog.Logger, Searcher Searcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		text := strings.TrimSpace(c.Query("q"))
+		if text == "" {
+			logHandler.Error("empty search query")
+
+			c.JSON(http.StatusBadRequest, response.Error("parameter q is required"))
+
+			return
+		}
+
+		var pag types.Pagination
+		var err error
+
+		pageQuery := c.DefaultQuery("page", defaultPage)
+
+		pag.Page, err = strconv.Atoi(pageQuery)
+		if err != nil || pag.Page < 1 {
+			logHandler.Error(list.ErrConvertParam.Error(), "param", "page", "query", pageQuery)
+
+			c.JSON(http.StatusBadRequest, response.Error(fmt.Sprintf("Invalid parameter:%s", pageQuery)))
+
+			return
+		}
+
+		limitQurey := c.DefaultQuery("limit", defaultLimit)
+
+		pag.Limit, err = strconv.Atoi(limitQurey)
+		if err != nil || pag.Limit < 1 {
+			logHandler.Error(list.ErrConvertParam.Error(), "param", "limit", "query", limitQurey)
+
+			c.JSON(http.StatusBadRequest, response.Error(fmt.Sprintf("Invalid parameter:%s", limitQurey)))
+
+			return
+		}
+
+		logHandler.Debug("Search query", "q", text, "page", pag.Page, "limit", pag.Limit)
+
+		users, count, err := Searcher.Search(ctx, text, pag.Offset(), pag.Limit)
+		if err != nil {
+			logHandler.Error("can't search persons", "err", err.Error())
+
+			c.JSON(http.StatusInternalServerError, response.Error("Internal Server Error"))
+			return
+		}
+
+		meta := &types.Meta{
+			Total:  count,
+			Limit:  pag.Limit,
+			Offset: pag.Offset(),
+			Next:   (pag.Offset() + pag.Limit) < count,
+		}
+
+		c.JSON(http.StatusOK, list.Response{Resp: response.OK(), Data: users, Meta: meta})
+
+	}
+}
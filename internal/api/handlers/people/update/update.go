@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"strconv"
 	"test-task/internal/domain/models"
+	"test-task/internal/lib/api/etag"
 	"test-task/internal/lib/api/response"
+	"test-task/internal/services/enrich"
 	"test-task/internal/storage"
 
 	"github.com/gin-contrib/requestid"
@@ -42,14 +44,20 @@ type UserProvider interface {
 // @Summary 	Update person data
 // @Description Update any field of person
 // @Description Need at least one field to update
+// @Description Age, gender and nationality set by update are not enriched anymore: their enrichment is dropped and person leaves review queue if they were unknown
+// @Description Nationality set by update drops nationality candidates of provider
+// @Description Send ETag of GET /people/{id} in If-Match to update only not changed since person. New ETag is returned in ETag header
 // @Tags 		people
 // @ID 			update
 // @Accept 		json
 // @Produce 	json
 // @Param		input		body		Request true "Person field data to update"
 // @Param       id			query		string	true  "id of person to update"
+// @Param       If-Match	header		string	false "ETag of person version the update is based on, list of ETags or *. Weak ETags never match" example("3")
 // @Success 200 {object}	Response	"OK - Returns a person fields with update"
+// @Header  200 {string}	ETag		"version of updated person"
 // @Failure 	400 "Invalid input"
+// @Failure 	412 {object}	response.Response "Person was changed since If-Match version"
 // @Failure 	500 "Internal error"
 // @Router 		/people [patch]
 func New(log *slog.Logger, Provider UserProvider, Updater PersonUpdater) gin.HandlerFunc {
@@ -70,6 +78,8 @@ func New(log *slog.Logger, Provider UserProvider, Updater PersonUpdater) gin.Han
 			logHandler.Error("can't param ID make int64")
 
 			c.JSON(http.StatusBadRequest, response.Error("Invalid ID"))
+
+			return
 		}
 
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -115,10 +125,37 @@ func New(log *slog.Logger, Provider UserProvider, Updater PersonUpdater) gin.Han
 			return
 		}
 
+		// person is updated if it's still of read version, so matched If-Match holds till commit
+		if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+			match, err := etag.ParseIfMatch(ifMatch)
+			if err != nil {
+				logHandler.Error("invalid If-Match", "err", err.Error())
+
+				c.JSON(http.StatusBadRequest, response.Error("Invalid If-Match header"))
+
+				return
+			}
+
+			if !match.Matches(person.Version) {
+				logHandler.Error("If-Match doesn't match person version", "ifMatch", ifMatch, "version", person.Version)
+
+				c.JSON(http.StatusPreconditionFailed, response.Error("Person was changed. Get it again and retry"))
+
+				return
+			}
+		}
+
 		checkForUpdates(logHandler, req, person)
 
 		err = Updater.Update(ctx, person, int64(id))
 		if err != nil {
+			if errors.Is(err, storage.ErrConflict) {
+				logHandler.Error("person was changed concurrently", "err", err.Error())
+
+				c.JSON(http.StatusPreconditionFailed, response.Error("Person was changed. Get it again and retry"))
+
+				return
+			}
 			if errors.Is(err, storage.ErrIDNotFound) {
 				logHandler.Error("personID not found", "err", err.Error())
 
@@ -135,12 +172,14 @@ func New(log *slog.Logger, Provider UserProvider, Updater PersonUpdater) gin.Han
 
 		logHandler.Info("person updated", "Person", person, "id", id)
 
+		c.Header("ETag", etag.Format(person.Version))
+
 		c.JSON(http.StatusOK, Response{Respone: response.OK(), Person: *person})
 
 	}
 }
 
-// 0_0
+// 0_0. Enrichment of attributes set manually is reset
 func checkForUpdates(log *slog.Logger, req Request, person *models.Person) {
 
 	if req.Name != nil {
@@ -157,14 +196,19 @@ func checkForUpdates(log *slog.Logger, req Request, person *models.Person) {
 	}
 	if req.Age != nil {
 		person.Age = *req.Age
+		person.ResetEnrichment(string(enrich.AttributeAge))
 		log.Debug("Field Age changed")
 	}
 	if req.Gender != nil {
 		person.Gender = *req.Gender
+		person.ResetEnrichment(string(enrich.AttributeGender))
 		log.Debug("Field Gender changed")
 	}
 	if req.Nationality != nil {
 		person.Nationality = *req.Nationality
+		// кандидаты провайдера больше не относятся к ручному значению
+		person.Nationalities = nil
+		person.ResetEnrichment(string(enrich.AttributeNationality))
 		log.Debug("Field Nationality changed")
 	}
 
@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+	"test-task/internal/lib/api/response"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// New checks "Authorization: Bearer <token>" header for admin routes.
+// Empty token disables admin routes at all
+func New(log *slog.Logger, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		logHandler := log.With(
+			slog.String("requestID", requestid.Get(c)),
+		)
+
+		if token == "" {
+			logHandler.Warn("admin API is disabled. ADMIN_TOKEN is not set")
+
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Error("Admin API is disabled"))
+
+			return
+		}
+
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			logHandler.Error("invalid admin token")
+
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error("Unauthorized"))
+
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"test-task/internal/lib/audit"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+const ActorHeader = "X-Actor"
+
+// New puts request ID and actor from X-Actor header into request context,
+// storage takes them from there to write history. Must be after requestid middleware.
+// Both come from client as is: actor is unauthenticated label, not audit identity
+func New() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := audit.WithMeta(c.Request.Context(), audit.Meta{
+			RequestID: requestid.Get(c),
+			Actor:     c.GetHeader(ActorHeader),
+		})
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -1,8 +1,10 @@
 package types
 
 type Meta struct {
-	Total  int  `form:"total"`
-	Limit  int  `form:"limit"`
-	Offset int  `form:"offset"`
-	Next   bool `form:"next"`
+	Total      int    `form:"total"`
+	Limit      int    `form:"limit"`
+	Offset     int    `form:"offset"`
+	Next       bool   `form:"next"`
+	NextCursor string `form:"next_cursor" json:"next_cursor,omitempty"`
+	PrevCursor string `form:"prev_cursor" json:"prev_cursor,omitempty"`
 }
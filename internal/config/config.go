@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -12,6 +13,58 @@ type Config struct {
 	ServerHost   string `env:"SRV_HOST"`
 	ServerPort   string `env:"SRV_PORT" env-default:"8080"`
 	DbConnString string `env:"DB_CONN_STRING, required"`
+	CursorSecret string `env:"CURSOR_SECRET"`
+
+	EnrichPartial             bool          `env:"ENRICH_PARTIAL" env-default:"false"`
+	EnrichLocalized           bool          `env:"ENRICH_LOCALIZED" env-default:"true"`
+	EnrichProviders           []string      `env:"ENRICH_PROVIDERS" env-default:"agify,genderize,nationalize"`
+	AgifyURL                  string        `env:"AGIFY_URL" env-default:"https://api.agify.io/"`
+	AgifyTimeout              time.Duration `env:"AGIFY_TIMEOUT" env-default:"60s"`
+	AgifyAPIKey               string        `env:"AGIFY_API_KEY"`
+	AgifyRetryAttempts        int           `env:"AGIFY_RETRY_ATTEMPTS" env-default:"3"`
+	AgifyRetryBaseDelay       time.Duration `env:"AGIFY_RETRY_BASE_DELAY" env-default:"200ms"`
+	AgifyRetryMaxDelay        time.Duration `env:"AGIFY_RETRY_MAX_DELAY" env-default:"5s"`
+	GenderizeURL              string        `env:"GENDERIZE_URL" env-default:"https://api.genderize.io/"`
+	GenderizeTimeout          time.Duration `env:"GENDERIZE_TIMEOUT" env-default:"60s"`
+	GenderizeAPIKey           string        `env:"GENDERIZE_API_KEY"`
+	GenderizeRetryAttempts    int           `env:"GENDERIZE_RETRY_ATTEMPTS" env-default:"3"`
+	GenderizeRetryBaseDelay   time.Duration `env:"GENDERIZE_RETRY_BASE_DELAY" env-default:"200ms"`
+	GenderizeRetryMaxDelay    time.Duration `env:"GENDERIZE_RETRY_MAX_DELAY" env-default:"5s"`
+	NationalizeURL            string        `env:"NATIONALIZE_URL" env-default:"https://api.nationalize.io/"`
+	NationalizeTimeout        time.Duration `env:"NATIONALIZE_TIMEOUT" env-default:"60s"`
+	NationalizeAPIKey         string        `env:"NATIONALIZE_API_KEY"`
+	NationalizeRetryAttempts  int           `env:"NATIONALIZE_RETRY_ATTEMPTS" env-default:"3"`
+	NationalizeRetryBaseDelay time.Duration `env:"NATIONALIZE_RETRY_BASE_DELAY" env-default:"200ms"`
+	NationalizeRetryMaxDelay  time.Duration `env:"NATIONALIZE_RETRY_MAX_DELAY" env-default:"5s"`
+	EnrichCacheSize           int           `env:"ENRICH_CACHE_SIZE" env-default:"10000"`
+	EnrichCacheTTL            time.Duration `env:"ENRICH_CACHE_TTL" env-default:"168h"`
+	BreakerFailures           int           `env:"ENRICH_BREAKER_FAILURES" env-default:"5"`
+	BreakerOpenTimeout        time.Duration `env:"ENRICH_BREAKER_OPEN_TIMEOUT" env-default:"30s"`
+	BreakerHalfOpenRequests   int           `env:"ENRICH_BREAKER_HALF_OPEN_REQUESTS" env-default:"1"`
+	RateLimit                 int           `env:"ENRICH_RATE_LIMIT" env-default:"0"`
+	RateLimitPeriod           time.Duration `env:"ENRICH_RATE_LIMIT_PERIOD" env-default:"24h"`
+	RateLimitPolicy           string        `env:"ENRICH_RATE_LIMIT_POLICY" env-default:"queue"`
+	RateLimitMaxWait          time.Duration `env:"ENRICH_RATE_LIMIT_MAX_WAIT" env-default:"10s"`
+	BatchWindow               time.Duration `env:"ENRICH_BATCH_WINDOW" env-default:"0"`
+	BatchSize                 int           `env:"ENRICH_BATCH_SIZE" env-default:"10"`
+	AgeMinCount               int           `env:"ENRICH_AGE_MIN_COUNT" env-default:"0"`
+	GenderMinProbability      float64       `env:"ENRICH_GENDER_MIN_PROBABILITY" env-default:"0"`
+	GenderMinCount            int           `env:"ENRICH_GENDER_MIN_COUNT" env-default:"0"`
+	NationalityMinProbability float64       `env:"ENRICH_NATIONALITY_MIN_PROBABILITY" env-default:"0"`
+	NationalityMinCount       int           `env:"ENRICH_NATIONALITY_MIN_COUNT" env-default:"0"`
+
+	EnrichAsync        bool          `env:"ENRICH_ASYNC" env-default:"false"`
+	EnrichWorkers      int           `env:"ENRICH_WORKERS" env-default:"4"`
+	EnrichPollInterval time.Duration `env:"ENRICH_POLL_INTERVAL" env-default:"1s"`
+	EnrichLease        time.Duration `env:"ENRICH_LEASE" env-default:"2m"`
+	EnrichMaxAttempts  int           `env:"ENRICH_MAX_ATTEMPTS" env-default:"5"`
+	EnrichRetryDelay   time.Duration `env:"ENRICH_RETRY_DELAY" env-default:"10s"`
+
+	BatchMaxSize           int `env:"BATCH_MAX_SIZE" env-default:"1000"`
+	BatchEnrichConcurrency int `env:"BATCH_ENRICH_CONCURRENCY" env-default:"8"`
+
+	AdminToken     string        `env:"ADMIN_TOKEN"`
+	PurgeRetention time.Duration `env:"PURGE_RETENTION" env-default:"720h"`
 }
 
 func MustRead() *Config {
@@ -1,12 +1,21 @@
 package filters
 
 type Options struct {
-	Name        *string `form:"name"`        // фильтр по имени (например, ?name=Иван)
-	Surname     *string `form:"surname"`     // по фамилии
-	Patronymic  *string `form:"patronymic"`  // по отчеству
-	Age         *int    `form:"age"`         // точный возраст
-	MinAge      *int    `form:"min_age"`     // возраст от
-	MaxAge      *int    `form:"max_age"`     // возраст до
-	Gender      *string `form:"gender"`      // "male"/"female"
-	Nationality *string `form:"nationality"` // "ru", "us" и т.д.
+	Name                   *string     `form:"name"`                    // фильтр по имени (например, ?name=Иван)
+	NamePrefix             *string     `form:"name_prefix"`             // имя начинается с (без учета регистра)
+	NameContains           *string     `form:"name_contains"`           // имя содержит (без учета регистра)
+	Surname                *string     `form:"surname"`                 // по фамилии
+	SurnamePrefix          *string     `form:"surname_prefix"`          // фамилия начинается с
+	SurnameContains        *string     `form:"surname_contains"`        // фамилия содержит
+	Patronymic             *string     `form:"patronymic"`              // по отчеству
+	PatronymicPrefix       *string     `form:"patronymic_prefix"`       // отчество начинается с
+	PatronymicContains     *string     `form:"patronymic_contains"`     // отчество содержит
+	IgnoreCase             bool        `form:"ignore_case"`             // точное совпадение name/surname/patronymic без учета регистра
+	Age                    *int        `form:"age"`                     // точный возраст
+	MinAge                 *int        `form:"min_age"`                 // возраст от
+	MaxAge                 *int        `form:"max_age"`                 // возраст до
+	Gender                 *string     `form:"gender"`                  // "male"/"female"
+	Nationality            *string     `form:"nationality"`             // "ru", "us" и т.д.
+	NationalityProbability *float64    `form:"nationality_probability"` // nationality или кандидат nationalize с вероятностью не ниже
+	Sort                   []SortField `form:"-"`                       // сортировка (например, ?sort=surname,-age)
 }
@@ -0,0 +1,13 @@
+package filters
+
+import "errors"
+
+var ErrInvalidKeyset = errors.New("invalid keyset")
+
+// Keyset - position for cursor pagination.
+// Values holds sort columns (id included) of the boundary row,
+// Backward means page before this row, otherwise after it
+type Keyset struct {
+	Values   map[string]any `json:"v"`
+	Backward bool           `json:"b,omitempty"`
+}
@@ -0,0 +1,70 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidSort = errors.New("invalid sort parameter")
+
+// SortField - one element of sort spec. Field is a column name, Desc switches direction
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses sort query like "surname,-age,created_at" into sort spec.
+// Leading "-" means descending order, "+" or nothing - ascending.
+func ParseSort(raw string) ([]SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	sort := make([]SortField, 0, len(parts))
+	seen := make(map[string]struct{}, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+
+		field := SortField{Field: part}
+
+		switch {
+		case strings.HasPrefix(part, "-"):
+			field.Field = part[1:]
+			field.Desc = true
+		case strings.HasPrefix(part, "+"):
+			field.Field = part[1:]
+		}
+
+		field.Field = strings.ToLower(strings.TrimSpace(field.Field))
+		if field.Field == "" {
+			return nil, fmt.Errorf("%w:empty field in %q", ErrInvalidSort, raw)
+		}
+
+		if _, ok := seen[field.Field]; ok {
+			return nil, fmt.Errorf("%w:duplicated field %s", ErrInvalidSort, field.Field)
+		}
+		seen[field.Field] = struct{}{}
+
+		sort = append(sort, field)
+	}
+
+	return sort, nil
+}
+
+// FormatSort builds sort query back from sort spec
+func FormatSort(sort []SortField) string {
+	parts := make([]string, 0, len(sort))
+
+	for _, field := range sort {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+			continue
+		}
+		parts = append(parts, field.Field)
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,57 @@
+package filters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []SortField
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			raw:  "",
+			want: nil,
+		},
+		{
+			name: "multi field",
+			raw:  "surname,-age, +created_at",
+			want: []SortField{
+				{Field: "surname"},
+				{Field: "age", Desc: true},
+				{Field: "created_at"},
+			},
+		},
+		{
+			name:    "empty field",
+			raw:     "surname,,age",
+			wantErr: true,
+		},
+		{
+			name:    "only direction",
+			raw:     "-",
+			wantErr: true,
+		},
+		{
+			name:    "duplicated field",
+			raw:     "age,-age",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSort(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSort() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
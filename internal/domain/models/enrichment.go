@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Enrichment - cached answer of enrichment provider for name, Country is empty if answer is not localized
+type Enrichment struct {
+	Name        string
+	Attribute   string
+	Country     string
+	Value       string
+	Probability float64
+	Count       int
+	Candidates  []Candidate
+	FetchedAt   time.Time
+}
+
+// Candidate - one of ranked values returned by provider
+type Candidate struct {
+	Value       string  `json:"value"`
+	Probability float64 `json:"probability"`
+}
+
+// EnrichmentTask - person claimed from enrichment queue. Attempt counts from 1
+type EnrichmentTask struct {
+	Person  *Person
+	Attempt int
+}
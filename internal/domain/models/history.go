@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	OperationCreate  = "create"
+	OperationUpdate  = "update"
+	OperationDelete  = "delete"
+	OperationRestore = "restore"
+	OperationPurge   = "purge"
+)
+
+// PersonChange - one record of person history. Values are keyed by column names,
+// OldValues is nil for create, NewValues is nil for delete. Purge has no values,
+// the last ones are in delete before it
+type PersonChange struct {
+	ID        int64          `json:"id"`
+	PersonID  int64          `json:"person_id"`
+	Operation string         `json:"operation"`
+	OldValues map[string]any `json:"old_values"`
+	NewValues map[string]any `json:"new_values"`
+	RequestID string         `json:"request_id"`
+	Actor     string         `json:"actor"`
+	ChangedAt time.Time      `json:"changed_at"`
+}
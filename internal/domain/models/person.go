@@ -1,10 +1,93 @@
 package models
 
+import "time"
+
+const (
+	EnrichmentPending    = "pending"
+	EnrichmentProcessing = "processing"
+	EnrichmentDone       = "done"
+	EnrichmentFailed     = "failed"
+)
+
+// statuses of enriched attribute
+const (
+	AttributeEnriched = "enriched"
+	AttributeNotFound = "not_found" // provider knows nothing about name
+	AttributeFailed   = "failed"    // provider failed, attribute is retried later
+	AttributeUnknown  = "unknown"   // value is below confidence threshold, kept in enrichment only, needs review
+)
+
+// AttributeEnrichment - result and provenance of the last enrichment of one attribute.
+// Count is sample size of provider for the name
+type AttributeEnrichment struct {
+	Status      string    `json:"status"`
+	Value       string    `json:"value,omitempty"`
+	Probability float64   `json:"probability,omitempty"`
+	Count       int       `json:"count,omitempty"`
+	Provider    string    `json:"provider,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at,omitzero"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// NationalityCandidate - country returned by nationalize for person name
+type NationalityCandidate struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// Person - Enrichment is keyed by attribute: age, gender, nationality.
+// Zero Age, Gender and Nationality are not enriched and stored as null.
+// Nationalities are candidates of nationality provider for the name ordered by probability,
+// empty if Nationality was not enriched. CountryHint localizes enrichment of age and gender
 type Person struct {
-	Name        string
-	Surname     string
-	Patronymic  string
-	Age         int
-	Gender      string
-	Nationality string
+	ID               int64                           `json:"id"`
+	Name             string                          `json:"name"`
+	Surname          string                          `json:"surname"`
+	Patronymic       string                          `json:"patronymic"`
+	Age              int                             `json:"age"`
+	Gender           string                          `json:"gender"`
+	Nationality      string                          `json:"nationality"`
+	Nationalities    []NationalityCandidate          `json:"nationalities,omitempty"`
+	CountryHint      string                          `json:"country_hint,omitempty"`
+	EnrichmentStatus string                          `json:"enrichment_status"`
+	Enrichment       map[string]*AttributeEnrichment `json:"enrichment,omitempty"`
+	CreatedAt        time.Time                       `json:"created_at"`
+	UpdatedAt        time.Time                       `json:"updated_at"`
+	Version          int                             `json:"version"`
+}
+
+// SetEnrichment sets enrichment status of attribute
+func (p *Person) SetEnrichment(attribute string, enrichment *AttributeEnrichment) {
+	if p.Enrichment == nil {
+		p.Enrichment = make(map[string]*AttributeEnrichment)
+	}
+
+	p.Enrichment[attribute] = enrichment
+}
+
+// ResetEnrichment forgets enrichment of attribute set manually
+func (p *Person) ResetEnrichment(attribute string) {
+	delete(p.Enrichment, attribute)
+}
+
+// NeedsReview reports whether some attribute value is unknown and must be set manually
+func (p *Person) NeedsReview() bool {
+	for _, enrichment := range p.Enrichment {
+		if enrichment.Status == AttributeUnknown {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnrichmentFailed reports whether some attribute must be enriched again
+func (p *Person) EnrichmentFailed() bool {
+	for _, enrichment := range p.Enrichment {
+		if enrichment.Status == AttributeFailed {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,82 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const secretLen = 32
+
+// Signer - encodes any payload into opaque string signed by HMAC-SHA256
+// and decodes it back checking the signature
+type Signer struct {
+	secret []byte
+}
+
+// New creates signer. If secret is empty random one is generated,
+// so cursors stop working after restart
+func New(secret string) (*Signer, error) {
+	if secret != "" {
+		return &Signer{secret: []byte(secret)}, nil
+	}
+
+	random := make([]byte, secretLen)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("can't generate cursor secret:%w", err)
+	}
+
+	return &Signer{secret: random}, nil
+}
+
+func (s *Signer) Encode(payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("can't marshal cursor:%w", err)
+	}
+
+	body := base64.RawURLEncoding.EncodeToString(data)
+
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.sign(body)), nil
+}
+
+func (s *Signer) Decode(cursor string, payload any) error {
+	body, sign, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return fmt.Errorf("%w:bad format", ErrInvalidCursor)
+	}
+
+	gotSign, err := base64.RawURLEncoding.DecodeString(sign)
+	if err != nil {
+		return fmt.Errorf("%w:bad signature encoding", ErrInvalidCursor)
+	}
+
+	if !hmac.Equal(gotSign, s.sign(body)) {
+		return fmt.Errorf("%w:signature mismatch", ErrInvalidCursor)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return fmt.Errorf("%w:bad body encoding", ErrInvalidCursor)
+	}
+
+	if err := json.Unmarshal(data, payload); err != nil {
+		return fmt.Errorf("%w:%w", ErrInvalidCursor, err)
+	}
+
+	return nil
+}
+
+func (s *Signer) sign(body string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(body))
+
+	return mac.Sum(nil)
+}
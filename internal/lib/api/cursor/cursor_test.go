@@ -0,0 +1,55 @@
+package cursor
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSigner_EncodeDecode(t *testing.T) {
+	type payload struct {
+		Sort string         `json:"s"`
+		Keys map[string]any `json:"k"`
+	}
+
+	signer, err := New("secret")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	want := payload{Sort: "surname,-age", Keys: map[string]any{"id": float64(12), "surname": "Ivanov"}}
+
+	encoded, err := signer.Encode(want)
+	if err != nil {
+		t.Fatalf("Signer.Encode() error = %v", err)
+	}
+
+	var got payload
+	if err := signer.Decode(encoded, &got); err != nil {
+		t.Fatalf("Signer.Decode() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Signer.Decode() = %v, want %v", got, want)
+	}
+
+	other, _ := New("other secret")
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		cursor string
+	}{
+		{name: "foreign secret", signer: other, cursor: encoded},
+		{name: "tampered body", signer: signer, cursor: "x" + encoded},
+		{name: "no signature", signer: signer, cursor: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got payload
+			if err := tt.signer.Decode(tt.cursor, &got); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Signer.Decode() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
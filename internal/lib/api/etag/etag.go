@@ -0,0 +1,89 @@
+package etag
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidETag = errors.New("invalid ETag")
+
+// Any - If-Match value matching any current version
+const Any = "*"
+
+// Format makes strong ETag from version: 3 -> "3"
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Parse returns version of strong ETag. Weak ETag is invalid here
+func Parse(value string) (int, error) {
+	value = strings.TrimSpace(value)
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil || !strings.HasPrefix(value, `"`) {
+		return 0, fmt.Errorf("%w:%s", ErrInvalidETag, value)
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%w:%s", ErrInvalidETag, value)
+	}
+
+	return version, nil
+}
+
+// IfMatch - parsed If-Match header. Any matches every current version,
+// otherwise only listed Versions match
+type IfMatch struct {
+	Any      bool
+	Versions []int
+}
+
+// Matches reports whether version satisfies If-Match
+func (m IfMatch) Matches(version int) bool {
+	if m.Any {
+		return true
+	}
+
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseIfMatch parses "*" or comma separated list of ETags.
+// If-Match uses strong comparison, so weak ETags are valid, but never match
+func ParseIfMatch(value string) (IfMatch, error) {
+	value = strings.TrimSpace(value)
+
+	if value == Any {
+		return IfMatch{Any: true}, nil
+	}
+
+	var m IfMatch
+
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if weak, ok := strings.CutPrefix(tag, "W/"); ok {
+			if _, err := Parse(weak); err != nil {
+				return IfMatch{}, err
+			}
+			continue
+		}
+
+		version, err := Parse(tag)
+		if err != nil {
+			return IfMatch{}, err
+		}
+
+		m.Versions = append(m.Versions, version)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,80 @@
+package etag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "strong", value: `"3"`, want: 3},
+		{name: "spaces", value: ` "12" `, want: 12},
+		{name: "round trip", value: Format(7), want: 7},
+		{name: "weak", value: `W/"12"`, wantErr: true},
+		{name: "not quoted", value: `3`, wantErr: true},
+		{name: "not a number", value: `"abc"`, wantErr: true},
+		{name: "zero", value: `"0"`, wantErr: true},
+		{name: "list", value: `"1", "2"`, wantErr: true},
+		{name: "any", value: `*`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		want      IfMatch
+		wantErr   bool
+		matches   []int
+		unmatches []int
+	}{
+		{name: "strong", value: `"3"`, want: IfMatch{Versions: []int{3}}, matches: []int{3}, unmatches: []int{2}},
+		{name: "any", value: ` * `, want: IfMatch{Any: true}, matches: []int{1, 3}},
+		{name: "weak never matches", value: `W/"3"`, want: IfMatch{}, unmatches: []int{3}},
+		{name: "list", value: `"1", "3" ,"5"`, want: IfMatch{Versions: []int{1, 3, 5}}, matches: []int{1, 3, 5}, unmatches: []int{2}},
+		{name: "list with weak", value: `W/"2", "3"`, want: IfMatch{Versions: []int{3}}, matches: []int{3}, unmatches: []int{2}},
+		{name: "any in list", value: `"1", *`, wantErr: true},
+		{name: "invalid weak", value: `W/3`, wantErr: true},
+		{name: "empty item", value: `"1",`, wantErr: true},
+		{name: "not a number", value: `"abc"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIfMatch(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseIfMatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseIfMatch() = %+v, want %+v", got, tt.want)
+			}
+			for _, version := range tt.matches {
+				if !got.Matches(version) {
+					t.Errorf("ParseIfMatch(%s).Matches(%d) = false, want true", tt.value, version)
+				}
+			}
+			for _, version := range tt.unmatches {
+				if got.Matches(version) {
+					t.Errorf("ParseIfMatch(%s).Matches(%d) = true, want false", tt.value, version)
+				}
+			}
+		})
+	}
+}
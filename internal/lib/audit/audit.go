@@ -0,0 +1,29 @@
+package audit
+
+import "context"
+
+const DefaultActor = "anonymous"
+
+// Meta - who and within which request changes data, as told by client.
+// Nothing is authenticated, Actor is only a label
+type Meta struct {
+	RequestID string
+	Actor     string
+}
+
+type ctxKey struct{}
+
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, ctxKey{}, meta)
+}
+
+// FromContext returns audit meta of request. Actor is DefaultActor if not set
+func FromContext(ctx context.Context) Meta {
+	meta, _ := ctx.Value(ctxKey{}).(Meta)
+
+	if meta.Actor == "" {
+		meta.Actor = DefaultActor
+	}
+
+	return meta
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want Meta
+	}{
+		{
+			name: "empty context",
+			ctx:  context.Background(),
+			want: Meta{Actor: DefaultActor},
+		},
+		{
+			name: "no actor",
+			ctx:  WithMeta(context.Background(), Meta{RequestID: "req-1"}),
+			want: Meta{RequestID: "req-1", Actor: DefaultActor},
+		},
+		{
+			name: "full meta",
+			ctx:  WithMeta(context.Background(), Meta{RequestID: "req-1", Actor: "support"}),
+			want: Meta{RequestID: "req-1", Actor: "support"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
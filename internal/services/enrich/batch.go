@@ -0,0 +1,177 @@
+package enrich
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MaxBatchSize - names accepted by providers in one request
+const MaxBatchSize = 10
+
+// DefaultBulkWindow - window of bulk import batches if batching of single lookups is off
+const DefaultBulkWindow = 20 * time.Millisecond
+
+// BatchConfig - lookups of provider coming within Window are sent in one request
+// of up to Size names. Zero Window disables batching
+type BatchConfig struct {
+	Window time.Duration
+	Size   int
+}
+
+func (c BatchConfig) withDefaults() BatchConfig {
+	if c.Size < 1 || c.Size > MaxBatchSize {
+		c.Size = MaxBatchSize
+	}
+
+	return c
+}
+
+// BatchProvider - provider fetching many names in one request.
+// Results are in order of names
+type BatchProvider interface {
+	Provider
+	FetchBatch(ctx context.Context, names []string, country string) ([]*Result, error)
+}
+
+// batchProvider coalesces lookups of BatchProvider: the first lookup opens batch of its country,
+// batch is sent when it's full or Window is over. Every caller gets own copy of result.
+// Breaker, if set, counts every request once whatever number of callers waits for it
+type batchProvider struct {
+	BatchProvider
+	log     *slog.Logger
+	cfg     BatchConfig
+	breaker *Breaker
+
+	mu      sync.Mutex
+	pending map[string]*batch
+}
+
+// batch - names waiting for request, one request has one country
+type batch struct {
+	ctx     context.Context
+	country string
+	names   []string
+	waiters map[string][]chan batchResult
+	timer   *time.Timer
+}
+
+type batchResult struct {
+	result *Result
+	err    error
+}
+
+func newBatchProvider(log *slog.Logger, provider BatchProvider, breaker *Breaker, cfg BatchConfig) *batchProvider {
+	return &batchProvider{
+		BatchProvider: provider,
+		log:           log,
+		cfg:           cfg.withDefaults(),
+		breaker:       breaker,
+		pending:       make(map[string]*batch),
+	}
+}
+
+// Fetch waits for result of batch with name. Cancelled ctx stops waiting, but not the batch.
+// Open breaker fails fast without waiting for batch
+func (p *batchProvider) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	if p.breaker != nil && p.breaker.Status().State == BreakerOpen {
+		return nil, ErrBreakerOpen
+	}
+
+	// буфер, чтобы отправка батча не ждала ушедших по ctx
+	wait := make(chan batchResult, 1)
+
+	p.add(ctx, name, country, wait)
+
+	select {
+	case r := <-wait:
+		return r.result, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *batchProvider) add(ctx context.Context, name string, country string, wait chan batchResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, ok := p.pending[country]
+	if !ok {
+		b = &batch{
+			// batch outlives the first caller, but keeps its request values for logs
+			ctx:     context.WithoutCancel(ctx),
+			country: country,
+			waiters: make(map[string][]chan batchResult),
+		}
+		b.timer = time.AfterFunc(p.cfg.Window, func() { p.flush(b) })
+
+		p.pending[country] = b
+	}
+
+	if _, ok := b.waiters[name]; !ok {
+		b.names = append(b.names, name)
+	}
+	b.waiters[name] = append(b.waiters[name], wait)
+
+	if len(b.names) >= p.cfg.Size {
+		b.timer.Stop()
+		delete(p.pending, country)
+
+		go p.send(b)
+	}
+}
+
+// flush sends batch by timer if it's not sent as full yet
+func (p *batchProvider) flush(b *batch) {
+	p.mu.Lock()
+	if p.pending[b.country] != b {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.pending, b.country)
+	p.mu.Unlock()
+
+	p.send(b)
+}
+
+func (p *batchProvider) send(b *batch) {
+	p.log.Debug("sending enrichment batch", "provider", p.Name(), "names", len(b.names), "country", b.country)
+
+	results, err := p.fetchBatch(b)
+
+	fetchedAt := time.Now()
+
+	for i, name := range b.names {
+		for _, wait := range b.waiters[name] {
+			if err != nil {
+				wait <- batchResult{err: err}
+				continue
+			}
+
+			result := *results[i]
+			if result.FetchedAt.IsZero() {
+				result.FetchedAt = fetchedAt
+			}
+
+			wait <- batchResult{result: &result}
+		}
+	}
+}
+
+func (p *batchProvider) fetchBatch(b *batch) ([]*Result, error) {
+	if p.breaker == nil {
+		return p.BatchProvider.FetchBatch(b.ctx, b.names, b.country)
+	}
+
+	generation, err := p.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := p.BatchProvider.FetchBatch(b.ctx, b.names, b.country)
+
+	p.breaker.Done(generation, err)
+
+	return results, err
+}
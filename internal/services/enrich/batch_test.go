@@ -0,0 +1,189 @@
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"test-task/internal/domain/models"
+	"test-task/internal/services/enrich/enrichstub"
+	"testing"
+	"time"
+)
+
+func TestEnricher_EnrichBatched(t *testing.T) {
+	tests := []struct {
+		name         string
+		names        []string
+		size         int
+		wantRequests int
+	}{
+		{
+			name:         "one request",
+			names:        []string{"Oleg", "Anna", "Ivan", "Oleg", enrichstub.Unknown},
+			wantRequests: 1,
+		},
+		{
+			name:         "split by size",
+			names:        []string{"Oleg", "Anna", "Ivan", "Petr", "Maria"},
+			size:         2,
+			wantRequests: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := enrichstub.Start(enrichstub.Options{})
+			defer srv.Close()
+
+			e := newTestEnricher(t, srv, func(cfg *Config) {
+				cfg.Batch = BatchConfig{Window: 50 * time.Millisecond, Size: tt.size}
+			})
+
+			people := make([]*models.Person, len(tt.names))
+			errs := make([]error, len(tt.names))
+
+			var wg sync.WaitGroup
+			for i, name := range tt.names {
+				wg.Add(1)
+				go func(i int, name string) {
+					defer wg.Done()
+
+					people[i], errs[i] = e.Enrich(context.Background(), &models.Person{Name: name, Surname: "Petrov"})
+				}(i, name)
+			}
+			wg.Wait()
+
+			for i, name := range tt.names {
+				if errs[i] != nil {
+					t.Fatalf("Enricher.Enrich(%s) error = %v", name, errs[i])
+				}
+				if people[i].Age != enrichstub.Age(name) || people[i].Gender != enrichstub.Gender(name) {
+					t.Errorf("Enricher.Enrich(%s) = %d %s, want %d %s",
+						name, people[i].Age, people[i].Gender, enrichstub.Age(name), enrichstub.Gender(name))
+				}
+				if enrichment := people[i].Enrichment[string(AttributeAge)]; enrichment.FetchedAt.IsZero() {
+					t.Errorf("Enricher.Enrich(%s) age fetched at is zero", name)
+				}
+			}
+
+			for _, path := range []string{enrichstub.AgifyPath, enrichstub.GenderizePath, enrichstub.NationalizePath} {
+				if n := srv.Requests(path); n != tt.wantRequests {
+					t.Errorf("Requests(%s) = %d, want %d", path, n, tt.wantRequests)
+				}
+			}
+		})
+	}
+}
+
+func TestEnricher_EnrichBatchedError(t *testing.T) {
+	srv := enrichstub.Start(enrichstub.Options{})
+	defer srv.Close()
+
+	srv.FailNext(enrichstub.AgifyPath, http.StatusBadRequest, 1)
+
+	e := newTestEnricher(t, srv, func(cfg *Config) {
+		cfg.Batch = BatchConfig{Window: 50 * time.Millisecond}
+	})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			_, errs[i] = e.Enrich(context.Background(), &models.Person{Name: fmt.Sprintf("Name%d", i), Surname: "Petrov"})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err == nil {
+			t.Errorf("Enricher.Enrich(Name%d) error = nil, want error of failed batch", i)
+		}
+	}
+
+	if n := srv.Requests(enrichstub.AgifyPath); n != 1 {
+		t.Errorf("Requests(agify) = %d, want 1", n)
+	}
+}
+
+func TestEnricher_EnrichBatchedBreaker(t *testing.T) {
+	srv := enrichstub.Start(enrichstub.Options{})
+	defer srv.Close()
+
+	// every attempt of one batch fails
+	srv.FailNext(enrichstub.AgifyPath, http.StatusServiceUnavailable, 3)
+
+	e := newTestEnricher(t, srv, func(cfg *Config) {
+		cfg.Batch = BatchConfig{Window: 50 * time.Millisecond}
+		cfg.Breaker = BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			e.Enrich(context.Background(), &models.Person{Name: fmt.Sprintf("Name%d", i), Surname: "Petrov"})
+		}(i)
+	}
+	wg.Wait()
+
+	if n := srv.Requests(enrichstub.AgifyPath); n != 3 {
+		t.Fatalf("Requests(agify) = %d, want 3 attempts of one batch", n)
+	}
+
+	for _, status := range e.Breakers() {
+		if status.Provider != AgifyName {
+			continue
+		}
+		if status.State != BreakerClosed || status.Failures != 1 {
+			t.Errorf("agify breaker = %s with %d failures, want %s with 1", status.State, status.Failures, BreakerClosed)
+		}
+	}
+}
+
+func TestEnricher_EnrichAll(t *testing.T) {
+	tests := []struct {
+		name         string
+		people       int
+		wantRequests int
+	}{
+		{name: "one batch", people: 10, wantRequests: 1},
+		{name: "batches of ten", people: 25, wantRequests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := enrichstub.Start(enrichstub.Options{})
+			defer srv.Close()
+
+			// batching of single lookups is off by default
+			e := newTestEnricher(t, srv, func(cfg *Config) {
+				cfg.Localized = true
+			})
+
+			people := make([]*models.Person, tt.people)
+			for i := range people {
+				people[i] = &models.Person{Name: fmt.Sprintf("Name%d", i), Surname: "Petrov", CountryHint: "RU"}
+			}
+
+			for i, err := range e.EnrichAll(context.Background(), people) {
+				if err != nil {
+					t.Fatalf("Enricher.EnrichAll() error of %s = %v", people[i].Name, err)
+				}
+				if want := enrichstub.AgeIn(people[i].Name, "RU"); people[i].Age != want {
+					t.Errorf("Enricher.EnrichAll() age of %s = %d, want %d", people[i].Name, people[i].Age, want)
+				}
+			}
+
+			for _, path := range []string{enrichstub.AgifyPath, enrichstub.GenderizePath, enrichstub.NationalizePath} {
+				if n := srv.Requests(path); n != tt.wantRequests {
+					t.Errorf("Requests(%s) = %d, want %d", path, n, tt.wantRequests)
+				}
+			}
+		})
+	}
+}
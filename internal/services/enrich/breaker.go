@@ -0,0 +1,260 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrBreakerOpen = errors.New("provider circuit breaker is open")
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+const (
+	DefaultBreakerOpenTimeout      = 30 * time.Second
+	DefaultBreakerHalfOpenRequests = 1
+)
+
+// BreakerConfig - breaker opens after FailureThreshold failures in a row,
+// after OpenTimeout lets HalfOpenRequests trial calls through.
+// Zero FailureThreshold disables breakers
+type BreakerConfig struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+}
+
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = DefaultBreakerOpenTimeout
+	}
+	if c.HalfOpenRequests < 1 {
+		c.HalfOpenRequests = DefaultBreakerHalfOpenRequests
+	}
+
+	return c
+}
+
+// BreakerStatus - state of provider breaker. OpenedAt is zero if breaker never opened
+type BreakerStatus struct {
+	Provider string
+	State    BreakerState
+	Failures int
+	OpenedAt time.Time
+}
+
+// Breaker - circuit breaker of one provider. Generation changes with every state change,
+// so results of calls allowed in previous state are not counted
+type Breaker struct {
+	mu         sync.Mutex
+	log        *slog.Logger
+	cfg        BreakerConfig
+	provider   string
+	state      BreakerState
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	inFlight   int
+	now        func() time.Time
+}
+
+func NewBreaker(log *slog.Logger, provider string, cfg BreakerConfig) *Breaker {
+	return &Breaker{
+		log:      log,
+		cfg:      cfg.withDefaults(),
+		provider: provider,
+		state:    BreakerClosed,
+		now:      time.Now,
+	}
+}
+
+// Allow returns ErrBreakerOpen if call must fail fast.
+// Allowed call must be followed by Done with returned generation
+func (b *Breaker) Allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		if b.now().Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return 0, ErrBreakerOpen
+		}
+
+		b.setState(BreakerHalfOpen)
+		b.inFlight = 0
+
+		b.log.Info("circuit breaker half-open", "provider", b.provider)
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.inFlight >= b.cfg.HalfOpenRequests {
+			return 0, ErrBreakerOpen
+		}
+		b.inFlight++
+	}
+
+	return b.generation, nil
+}
+
+// Done records result of call allowed in generation. Results of previous generations are ignored:
+// call started before breaker opened can't close it. Errors of caller are not failures of provider
+func (b *Breaker) Done(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	if b.state == BreakerHalfOpen {
+		b.inFlight--
+	}
+
+	if err != nil && !providerFailure(err) {
+		return
+	}
+
+	if err == nil {
+		if b.state != BreakerClosed {
+			b.log.Info("circuit breaker closed", "provider", b.provider)
+
+			b.setState(BreakerClosed)
+		}
+
+		b.failures = 0
+
+		return
+	}
+
+	b.failures++
+
+	if b.state == BreakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.log.Warn("circuit breaker opened", "provider", b.provider, "failures", b.failures, "err", err.Error())
+
+		b.setState(BreakerOpen)
+		b.openedAt = b.now()
+	}
+}
+
+func (b *Breaker) setState(state BreakerState) {
+	b.state = state
+	b.generation++
+}
+
+// providerFailure reports whether err tells about provider health: network errors, timeouts of attempt,
+// error status and broken answers do. Caller errors don't: canceled call, exhausted local quota
+// and input rejected by provider (400, 422)
+func providerFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrQuotaExhausted) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		state = BreakerHalfOpen
+	}
+
+	return BreakerStatus{
+		Provider: b.provider,
+		State:    state,
+		Failures: b.failures,
+		OpenedAt: b.openedAt,
+	}
+}
+
+// breakers - breakers by provider name, created on first call
+type breakers struct {
+	mu   sync.Mutex
+	log  *slog.Logger
+	cfg  BreakerConfig
+	byID map[string]*Breaker
+}
+
+func newBreakers(log *slog.Logger, cfg BreakerConfig) *breakers {
+	return &breakers{
+		log:  log,
+		cfg:  cfg,
+		byID: make(map[string]*Breaker),
+	}
+}
+
+func (b *breakers) get(provider string) *Breaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	breaker, ok := b.byID[provider]
+	if !ok {
+		breaker = NewBreaker(b.log, provider, b.cfg)
+		b.byID[provider] = breaker
+	}
+
+	return breaker
+}
+
+// breakerProvider - provider behind circuit breaker
+type breakerProvider struct {
+	Provider
+	breaker *Breaker
+}
+
+func (p breakerProvider) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	generation, err := p.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.Provider.Fetch(ctx, name, country)
+
+	outcome := err
+	if err != nil && ctx.Err() != nil {
+		// caller ran out of own deadline, provider is not to blame
+		outcome = context.Canceled
+	}
+
+	p.breaker.Done(generation, outcome)
+
+	return result, err
+}
+
+// Breakers returns breaker states of registered providers sorted by provider name
+func (e *Enricher) Breakers() []BreakerStatus {
+	statuses := make([]BreakerStatus, 0)
+
+	if e.breakers == nil {
+		return statuses
+	}
+
+	for _, provider := range e.registry.Providers() {
+		statuses = append(statuses, e.breakers.get(provider.Name()).Status())
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Provider < statuses[j].Provider
+	})
+
+	return statuses
+}
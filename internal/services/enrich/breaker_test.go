@@ -0,0 +1,183 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	errFetch := &StatusError{StatusCode: http.StatusServiceUnavailable}
+
+	now := time.Now()
+
+	b := NewBreaker(log, AgifyName, BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+	b.now = func() time.Time { return now }
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		err       error
+		wantAllow error
+		wantState BreakerState
+	}{
+		{name: "failure below threshold", err: errFetch, wantState: BreakerClosed},
+		{name: "success resets failures", wantState: BreakerClosed},
+		{name: "failure", err: errFetch, wantState: BreakerClosed},
+		{name: "canceled is not counted", err: context.Canceled, wantState: BreakerClosed},
+		{name: "bad request is not counted", err: &StatusError{StatusCode: http.StatusBadRequest}, wantState: BreakerClosed},
+		{name: "quota is not counted", err: ErrQuotaExhausted, wantState: BreakerClosed},
+		{name: "threshold opens", err: errFetch, wantState: BreakerOpen},
+		{name: "open fails fast", advance: time.Second, wantAllow: ErrBreakerOpen, wantState: BreakerOpen},
+		{name: "trial call fails", advance: time.Minute, err: errFetch, wantState: BreakerOpen},
+		{name: "trial call succeeds", advance: time.Minute, wantState: BreakerClosed},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+
+		generation, err := b.Allow()
+		if !errors.Is(err, step.wantAllow) {
+			t.Fatalf("%s: Allow() error = %v, want %v", step.name, err, step.wantAllow)
+		}
+		if err == nil {
+			b.Done(generation, step.err)
+		}
+
+		if got := b.Status().State; got != step.wantState {
+			t.Fatalf("%s: state = %v, want %v", step.name, got, step.wantState)
+		}
+	}
+}
+
+func TestBreaker_HalfOpenRequests(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	now := time.Now()
+
+	b := NewBreaker(log, AgifyName, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenRequests: 1})
+	b.now = func() time.Time { return now }
+
+	generation, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	b.Done(generation, &requestError{err: errors.New("timeout")})
+
+	now = now.Add(time.Second)
+
+	if _, err := b.Allow(); err != nil {
+		t.Fatalf("trial Allow() error = %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrBreakerOpen) {
+		t.Errorf("second trial Allow() error = %v, want %v", err, ErrBreakerOpen)
+	}
+}
+
+func TestBreaker_ProviderFailures(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name     string
+		err      error
+		wantOpen bool
+	}{
+		{name: "server error", err: &StatusError{StatusCode: http.StatusInternalServerError}, wantOpen: true},
+		{name: "invalid api key", err: &StatusError{StatusCode: http.StatusUnauthorized}, wantOpen: true},
+		{name: "malformed answer", err: fmt.Errorf("json unmarshal failed: %w", errors.New("unexpected end of JSON input")), wantOpen: true},
+		{name: "attempt timeout", err: &requestError{err: context.DeadlineExceeded}, wantOpen: true},
+		{name: "unprocessable name", err: &StatusError{StatusCode: http.StatusUnprocessableEntity}},
+		{name: "canceled", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker(log, AgifyName, BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute})
+
+			for i := 0; i < 3; i++ {
+				generation, err := b.Allow()
+				if err != nil {
+					t.Fatalf("Allow() error = %v", err)
+				}
+				b.Done(generation, tt.err)
+			}
+
+			if got := b.Status().State == BreakerOpen; got != tt.wantOpen {
+				t.Errorf("open = %v, want %v", got, tt.wantOpen)
+			}
+		})
+	}
+}
+
+func TestBreaker_StaleDone(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	now := time.Now()
+
+	b := NewBreaker(log, AgifyName, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	b.now = func() time.Time { return now }
+
+	slow, _ := b.Allow()
+	failing, _ := b.Allow()
+
+	b.Done(failing, &StatusError{StatusCode: http.StatusServiceUnavailable})
+
+	// call started before breaker opened succeeds later
+	b.Done(slow, nil)
+
+	if got := b.Status().State; got != BreakerOpen {
+		t.Fatalf("state after stale success = %v, want %v", got, BreakerOpen)
+	}
+
+	now = now.Add(time.Minute)
+
+	trial, err := b.Allow()
+	if err != nil {
+		t.Fatalf("trial Allow() error = %v", err)
+	}
+
+	// stale result doesn't take slot of trial call
+	b.Done(slow, nil)
+
+	if _, err := b.Allow(); !errors.Is(err, ErrBreakerOpen) {
+		t.Errorf("second trial Allow() error = %v, want %v", err, ErrBreakerOpen)
+	}
+
+	b.Done(trial, nil)
+
+	if got := b.Status().State; got != BreakerClosed {
+		t.Errorf("state after trial success = %v, want %v", got, BreakerClosed)
+	}
+}
+
+type timeoutProvider struct {
+	stubProvider
+}
+
+func (p timeoutProvider) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	<-ctx.Done()
+
+	return nil, &requestError{err: ctx.Err()}
+}
+
+func TestBreakerProvider_CallerDeadline(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	b := NewBreaker(log, AgifyName, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	p := breakerProvider{Provider: timeoutProvider{stubProvider{name: AgifyName, attribute: AttributeAge}}, breaker: b}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Fetch(ctx, "Dmitriy", ""); err == nil {
+		t.Fatal("Fetch() error = nil, want deadline error")
+	}
+
+	if got := b.Status().State; got != BreakerClosed {
+		t.Errorf("state = %v, want %v", got, BreakerClosed)
+	}
+}
@@ -0,0 +1,105 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"test-task/internal/domain/models"
+	"test-task/internal/storage"
+	"time"
+)
+
+// CacheStore - persistent cache of enrichment results
+type CacheStore interface {
+	GetEnrichment(ctx context.Context, name string, attribute string, country string, fetchedAfter time.Time) (*models.Enrichment, error)
+	SaveEnrichment(ctx context.Context, enrichment *models.Enrichment) error
+}
+
+// Cache - results of providers by name: in-memory LRU first, then store.
+// Store errors are logged only, cache never fails enrichment
+type Cache struct {
+	log   *slog.Logger
+	lru   *lru
+	store CacheStore
+	ttl   time.Duration
+}
+
+// NewCache creates cache. store can be nil - memory only
+func NewCache(log *slog.Logger, store CacheStore, size int, ttl time.Duration) *Cache {
+	return &Cache{
+		log:   log,
+		lru:   newLRU(size, ttl),
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Fetch returns cached result of provider attribute in country or fetches and caches it
+func (c *Cache) Fetch(ctx context.Context, provider Provider, name string, country string) (*Result, error) {
+	attribute := string(provider.Attribute())
+	key := cacheName(name)
+	lruKey := key + "|" + attribute + "|" + country
+
+	if result, ok := c.lru.Get(lruKey); ok {
+		c.log.Debug("enrichment cache hit", "name", name, "attribute", attribute, "cache", "memory")
+
+		return result, nil
+	}
+
+	if c.store != nil {
+		cached, err := c.store.GetEnrichment(ctx, key, attribute, country, time.Now().Add(-c.ttl))
+		switch {
+		case err == nil:
+			c.log.Debug("enrichment cache hit", "name", name, "attribute", attribute, "cache", "storage")
+
+			result := Result{
+				Value:       cached.Value,
+				Probability: cached.Probability,
+				Count:       cached.Count,
+				Candidates:  cached.Candidates,
+				FetchedAt:   cached.FetchedAt,
+			}
+			c.lru.Add(lruKey, result, cached.FetchedAt)
+
+			return &result, nil
+		case !errors.Is(err, storage.ErrCacheMiss):
+			c.log.Error("can't read enrichment cache", "err", err.Error())
+		}
+	}
+
+	result, err := provider.Fetch(ctx, name, country)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.FetchedAt.IsZero() {
+		result.FetchedAt = time.Now()
+	}
+	fetchedAt := result.FetchedAt
+
+	c.lru.Add(lruKey, *result, fetchedAt)
+
+	if c.store != nil {
+		err := c.store.SaveEnrichment(ctx, &models.Enrichment{
+			Name:        key,
+			Attribute:   attribute,
+			Country:     country,
+			Value:       result.Value,
+			Probability: result.Probability,
+			Count:       result.Count,
+			Candidates:  result.Candidates,
+			FetchedAt:   fetchedAt,
+		})
+		if err != nil {
+			c.log.Error("can't save enrichment cache", "err", err.Error())
+		}
+	}
+
+	return result, nil
+}
+
+// cacheName - "  Ivan " and "ivan" share cache
+func cacheName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
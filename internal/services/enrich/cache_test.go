@@ -0,0 +1,92 @@
+package enrich
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"test-task/internal/domain/models"
+	"test-task/internal/storage"
+	"testing"
+	"time"
+)
+
+type countingProvider struct {
+	stubProvider
+	calls int
+}
+
+func (p *countingProvider) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	p.calls++
+	return &Result{Value: "42", Probability: 0.5}, nil
+}
+
+type memStore map[string]models.Enrichment
+
+func (s memStore) GetEnrichment(ctx context.Context, name string, attribute string, country string, fetchedAfter time.Time) (*models.Enrichment, error) {
+	e, ok := s[name+"|"+attribute+"|"+country]
+	if !ok || !e.FetchedAt.After(fetchedAfter) {
+		return nil, storage.ErrCacheMiss
+	}
+	return &e, nil
+}
+
+func (s memStore) SaveEnrichment(ctx context.Context, enrichment *models.Enrichment) error {
+	s[enrichment.Name+"|"+enrichment.Attribute+"|"+enrichment.Country] = *enrichment
+	return nil
+}
+
+func TestCache_Fetch(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	provider := &countingProvider{stubProvider: stubProvider{name: "agify", attribute: AttributeAge}}
+	store := memStore{}
+
+	cache := NewCache(log, store, 10, time.Hour)
+
+	for _, name := range []string{"Ivan", " ivan "} {
+		got, err := cache.Fetch(ctx, provider, name, "")
+		if err != nil {
+			t.Fatalf("Cache.Fetch() error = %v", err)
+		}
+		if got.Value != "42" || got.Probability != 0.5 {
+			t.Errorf("Cache.Fetch() = %+v", got)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider called %d times, want 1", provider.calls)
+	}
+	if _, ok := store["ivan|age|"]; !ok {
+		t.Errorf("result is not saved to store: %v", store)
+	}
+
+	// new process: empty memory, filled store
+	cache = NewCache(log, store, 10, time.Hour)
+	if _, err := cache.Fetch(ctx, provider, "Ivan", ""); err != nil {
+		t.Fatalf("Cache.Fetch() error = %v", err)
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider called %d times, want 1", provider.calls)
+	}
+
+	// expired in store
+	store["ivan|age|"] = models.Enrichment{Name: "ivan", Attribute: "age", Value: "1", FetchedAt: time.Now().Add(-2 * time.Hour)}
+	cache = NewCache(log, store, 10, time.Hour)
+	if _, err := cache.Fetch(ctx, provider, "Ivan", ""); err != nil {
+		t.Fatalf("Cache.Fetch() error = %v", err)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider called %d times, want 2", provider.calls)
+	}
+
+	// localized answer is cached apart
+	if _, err := cache.Fetch(ctx, provider, "Ivan", "RU"); err != nil {
+		t.Fatalf("Cache.Fetch() error = %v", err)
+	}
+	if provider.calls != 3 {
+		t.Errorf("provider called %d times, want 3", provider.calls)
+	}
+	if _, ok := store["ivan|age|RU"]; !ok {
+		t.Errorf("localized result is not saved to store: %v", store)
+	}
+}
@@ -0,0 +1,61 @@
+package enrich
+
+import "time"
+
+const (
+	DefaultAgifyURL       = "https://api.agify.io/"
+	DefaultGenderizeURL   = "https://api.genderize.io/"
+	DefaultNationalizeURL = "https://api.nationalize.io/"
+	DefaultTimeout        = 60 * time.Second
+)
+
+// Config - enrichment settings. Providers are names of enabled providers.
+// Zero CacheTTL disables cache. Partial keeps attributes fetched when other providers failed.
+// Thresholds - minimal confidence of fetched value by attribute, less confident values are unknown.
+// Localized fetches nationality before age and gender to localize them if country of person is unknown.
+// Batch coalesces concurrent lookups of providers into multi-name requests
+type Config struct {
+	Providers   []string
+	Partial     bool
+	Localized   bool
+	CacheSize   int
+	CacheTTL    time.Duration
+	Breaker     BreakerConfig
+	RateLimit   RateLimitConfig
+	Batch       BatchConfig
+	Thresholds  map[Attribute]Threshold
+	Agify       ProviderConfig
+	Genderize   ProviderConfig
+	Nationalize ProviderConfig
+}
+
+// Threshold - zero fields don't limit anything. Provider without probability
+// returns 0, so MinProbability of such attribute makes every value unknown
+type Threshold struct {
+	MinProbability float64
+	MinCount       int
+}
+
+func (t Threshold) accepts(result *Result) bool {
+	return result.Probability >= t.MinProbability && result.Count >= t.MinCount
+}
+
+// ProviderConfig - endpoint of http provider. Empty fields are replaced by defaults
+type ProviderConfig struct {
+	BaseURL string
+	Timeout time.Duration
+	APIKey  string
+	Retry   RetryConfig
+}
+
+func (c ProviderConfig) withDefaults(baseURL string) ProviderConfig {
+	if c.BaseURL == "" {
+		c.BaseURL = baseURL
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	c.Retry = c.Retry.withDefaults()
+
+	return c
+}
@@ -2,62 +2,306 @@ package enrich
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"net/http"
+	"strconv"
 	"sync"
 	"test-task/internal/domain/models"
 	"time"
 )
 
-const (
-	AgeAPI         = "https://api.agify.io/?name="
-	GenderAPI      = "https://api.genderize.io/?name="
-	NationalityAPI = "https://api.nationalize.io/?name="
-	timeout        = 60 * time.Second
-)
-
 type Enricher struct {
-	log    *slog.Logger
-	client *http.Client
+	log        *slog.Logger
+	registry   *Registry
+	cache      *Cache
+	breakers   *breakers
+	thresholds map[Attribute]Threshold
+	degrade    bool
+	partial    bool
+	localized  bool
+	batch      BatchConfig
+}
+
+// New creates Enricher with builtin providers enabled by cfg.Providers.
+// Results are cached in memory and in store if it's not nil, cache misses are batched if cfg.Batch is set
+func New(log *slog.Logger, cfg Config, store CacheStore) (*Enricher, error) {
+	if err := cfg.RateLimit.validate(); err != nil {
+		return nil, err
+	}
+
+	registry, err := NewRegistryByNames(cfg.Providers, Builtin(log, cfg)...)
+	if err != nil {
+		return nil, err
+	}
+
+	e := NewWithRegistry(log, registry)
+
+	if cfg.CacheTTL > 0 {
+		e.cache = NewCache(log, store, cfg.CacheSize, cfg.CacheTTL)
+	}
+
+	if cfg.Breaker.FailureThreshold > 0 {
+		e.breakers = newBreakers(log, cfg.Breaker)
+	}
+
+	e.batch = cfg.Batch
+	if cfg.Batch.Window > 0 {
+		e.registry = e.batched(cfg.Batch)
+	}
+
+	e.thresholds = cfg.Thresholds
+	e.degrade = cfg.RateLimit.Policy == RateLimitDegrade
+	e.partial = cfg.Partial
+	e.localized = cfg.Localized
+
+	return e, nil
 }
 
-func New(log *slog.Logger) *Enricher {
+func NewWithRegistry(log *slog.Logger, registry *Registry) *Enricher {
 	return &Enricher{
-		client: &http.Client{Timeout: timeout},
-		log:    log,
+		log:      log,
+		registry: registry,
 	}
 }
 
+// Enrich fetches empty attributes of person in parallel and sets their enrichment status.
+// The first failed fetch cancels the others and fails enrichment,
+// in partial mode failed attributes are left empty with failed status instead.
+// Values below threshold are stored as unknown: field is left empty, the rejected value with its
+// probability and count is kept in Enrichment of attribute with unknown status, person needs review then.
+// Age and gender are localized by CountryHint or Nationality of person. Localized Enricher
+// without them fetches nationality first and localizes by it
 func (e *Enricher) Enrich(ctx context.Context, person *models.Person) (*models.Person, error) {
 
-	var wg sync.WaitGroup
-	ageResult := make(chan int, 1)
-	genderResult := make(chan string, 1)
-	nationalityResult := make(chan string, 1)
+	providers := make([]Provider, 0, 3)
+	first := make([]Provider, 0, 1)
 
-	errChan := make(chan error, 3)
+	country := person.CountryHint
+	if country == "" {
+		country = person.Nationality
+	}
 
-	wg.Add(3)
-	go e.fetchAge(ctx, person.Name, &wg, ageResult, errChan)
-	go e.fetchGender(ctx, person.Name, &wg, genderResult, errChan)
-	go e.fetchNationality(ctx, person.Name, &wg, nationalityResult, errChan)
+	for _, provider := range e.registry.Providers() {
+		if !isEmpty(person, provider.Attribute()) {
+			continue
+		}
 
-	wg.Wait()
+		if e.localized && country == "" && provider.Attribute() == AttributeNationality {
+			first = append(first, provider)
+			continue
+		}
 
-	close(errChan)
-	close(ageResult)
-	close(genderResult)
-	close(nationalityResult)
+		providers = append(providers, provider)
+	}
 
-	err, opened := <-errChan
-	if err != nil && !opened {
-		return nil, fmt.Errorf("failed to enrich person data: %w", err)
+	if err := e.enrich(ctx, person, first, ""); err != nil {
+		return nil, err
 	}
 
-	person.Age = <-ageResult
-	person.Gender = <-genderResult
-	person.Nationality = <-nationalityResult
+	if country == "" {
+		country = person.Nationality
+	}
+
+	if err := e.enrich(ctx, person, providers, country); err != nil {
+		return nil, err
+	}
 
 	return person, nil
 }
+
+// EnrichAll enriches people of bulk import at once. Their lookups are sent as name[] batches
+// even if batching of single lookups is off, then batch waits for names DefaultBulkWindow.
+// Errors are in order of people, every person is enriched as by Enrich
+func (e *Enricher) EnrichAll(ctx context.Context, people []*models.Person) []error {
+	bulk := e
+	if e.batch.Window <= 0 {
+		copied := *e
+		copied.registry = e.batched(BatchConfig{Window: DefaultBulkWindow, Size: e.batch.Size})
+
+		bulk = &copied
+	}
+
+	errs := make([]error, len(people))
+
+	var wg sync.WaitGroup
+	for i, person := range people {
+		wg.Add(1)
+		go func(i int, person *models.Person) {
+			defer wg.Done()
+
+			_, errs[i] = bulk.Enrich(ctx, person)
+		}(i, person)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// batched returns registry of e where providers able to fetch many names are batched by cfg
+func (e *Enricher) batched(cfg BatchConfig) *Registry {
+	providers := e.registry.Providers()
+
+	for i, provider := range providers {
+		batcher, ok := provider.(BatchProvider)
+		if !ok {
+			continue
+		}
+
+		var breaker *Breaker
+		if e.breakers != nil {
+			breaker = e.breakers.get(batcher.Name())
+		}
+
+		providers[i] = newBatchProvider(e.log, batcher, breaker, cfg)
+	}
+
+	return NewRegistry(providers...)
+}
+
+// enrich fetches attributes of providers in parallel, nationality is never localized
+func (e *Enricher) enrich(ctx context.Context, person *models.Person, providers []Provider, country string) error {
+
+	type fetched struct {
+		provider Provider
+		result   *Result
+		err      error
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// буфер на всех, чтобы отмененные запросы не висели на отправке
+	results := make(chan fetched, len(providers))
+
+	for _, provider := range providers {
+		country := country
+		if provider.Attribute() == AttributeNationality {
+			country = ""
+		}
+
+		go func(provider Provider, name string, country string) {
+			result, err := e.fetch(ctx, provider, name, country)
+			results <- fetched{provider: provider, result: result, err: err}
+		}(provider, person.Name, country)
+	}
+
+	for range providers {
+		f := <-results
+
+		attribute := string(f.provider.Attribute())
+
+		err := f.err
+		unknown := err == nil && f.result.Value != "" && !e.thresholds[f.provider.Attribute()].accepts(f.result)
+		if err == nil && f.result.Value != "" && !unknown {
+			err = apply(person, f.provider.Attribute(), f.result)
+		}
+
+		switch {
+		case err == nil && f.result.Value == "":
+			person.SetEnrichment(attribute, enrichment(models.AttributeNotFound, f.provider, f.result))
+		case unknown:
+			e.log.Info("attribute value is below threshold", "attribute", attribute,
+				"probability", f.result.Probability, "count", f.result.Count)
+
+			person.SetEnrichment(attribute, enrichment(models.AttributeUnknown, f.provider, f.result))
+		case err == nil:
+			person.SetEnrichment(attribute, enrichment(models.AttributeEnriched, f.provider, f.result))
+		case e.partial || e.degrade && errors.Is(err, ErrQuotaExhausted):
+			e.log.Warn("attribute is not enriched", "attribute", attribute, "err", err.Error())
+
+			person.SetEnrichment(attribute, &models.AttributeEnrichment{
+				Status:   models.AttributeFailed,
+				Provider: f.provider.Name(),
+				Error:    err.Error(),
+			})
+		default:
+			return fmt.Errorf("failed to enrich person data: %s: %w", f.provider.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// fetch asks cache first, so cached names are enriched even if provider breaker is open.
+// Batched provider has breaker inside: one request is one outcome, not one per caller
+func (e *Enricher) fetch(ctx context.Context, provider Provider, name string, country string) (*Result, error) {
+	if _, batched := provider.(*batchProvider); e.breakers != nil && !batched {
+		provider = breakerProvider{Provider: provider, breaker: e.breakers.get(provider.Name())}
+	}
+
+	if e.cache != nil {
+		return e.cache.Fetch(ctx, provider, name, country)
+	}
+
+	result, err := provider.Fetch(ctx, name, country)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.FetchedAt.IsZero() {
+		result.FetchedAt = time.Now()
+	}
+
+	return result, nil
+}
+
+// enrichment - provenance of fetched attribute
+func enrichment(status string, provider Provider, result *Result) *models.AttributeEnrichment {
+	return &models.AttributeEnrichment{
+		Status:      status,
+		Value:       result.Value,
+		Probability: result.Probability,
+		Count:       result.Count,
+		Provider:    provider.Name(),
+		FetchedAt:   result.FetchedAt,
+	}
+}
+
+func isEmpty(person *models.Person, attribute Attribute) bool {
+	switch attribute {
+	case AttributeAge:
+		return person.Age == 0
+	case AttributeGender:
+		return person.Gender == ""
+	case AttributeNationality:
+		return person.Nationality == ""
+	}
+
+	return true
+}
+
+// apply sets fetched value to person field of attribute
+func apply(person *models.Person, attribute Attribute, result *Result) error {
+	switch attribute {
+	case AttributeAge:
+		age, err := strconv.Atoi(result.Value)
+		if err != nil {
+			return fmt.Errorf("invalid age %q:%w", result.Value, err)
+		}
+		person.Age = age
+	case AttributeGender:
+		person.Gender = result.Value
+	case AttributeNationality:
+		person.Nationality = result.Value
+		person.Nationalities = nationalities(result)
+	default:
+		return fmt.Errorf("unsupported attribute %s", attribute)
+	}
+
+	return nil
+}
+
+// nationalities - provider without candidates gives the only one
+func nationalities(result *Result) []models.NationalityCandidate {
+	if len(result.Candidates) == 0 {
+		return []models.NationalityCandidate{{CountryID: result.Value, Probability: result.Probability}}
+	}
+
+	candidates := make([]models.NationalityCandidate, 0, len(result.Candidates))
+	for _, candidate := range result.Candidates {
+		candidates = append(candidates, models.NationalityCandidate{CountryID: candidate.Value, Probability: candidate.Probability})
+	}
+
+	return candidates
+}
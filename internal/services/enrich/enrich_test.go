@@ -2,67 +2,548 @@ package enrich
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"reflect"
+	"strconv"
 	"test-task/internal/domain/models"
-	"test-task/internal/logger"
+	"test-task/internal/services/enrich/enrichstub"
 	"testing"
+	"time"
 )
 
-func TestEnricher_Enrich(t *testing.T) {
-	type fields struct {
-		log    *slog.Logger
-		client *http.Client
+func newTestEnricher(t *testing.T, srv *enrichstub.Server, options ...func(cfg *Config)) *Enricher {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	retry := RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}
+
+	cfg := Config{
+		Providers:   []string{AgifyName, GenderizeName, NationalizeName},
+		Agify:       ProviderConfig{BaseURL: srv.AgifyURL(), Timeout: time.Second, Retry: retry},
+		Genderize:   ProviderConfig{BaseURL: srv.GenderizeURL(), Timeout: time.Second, Retry: retry},
+		Nationalize: ProviderConfig{BaseURL: srv.NationalizeURL(), Timeout: time.Second, Retry: retry},
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	e, err := New(log, cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	return e
+}
+
+// statuses builds Enrichment of person without errors
+func statuses(age, gender, nationality string) map[string]*models.AttributeEnrichment {
+	return map[string]*models.AttributeEnrichment{
+		string(AttributeAge):         {Status: age},
+		string(AttributeGender):      {Status: gender},
+		string(AttributeNationality): {Status: nationality},
 	}
-	type args struct {
-		ctx    context.Context
-		person *models.Person
+}
+
+// stubNationalities - candidates of name answered by stub
+func stubNationalities(name string) []models.NationalityCandidate {
+	var candidates []models.NationalityCandidate
+	for _, country := range enrichstub.Countries(name) {
+		candidates = append(candidates, models.NationalityCandidate{CountryID: country.CountryID, Probability: country.Probability})
+	}
+
+	return candidates
+}
+
+// onlyStatuses leaves statuses of attributes enrichment to compare persons
+func onlyStatuses(person *models.Person) {
+	if person == nil {
+		return
 	}
+
+	for attribute, enrichment := range person.Enrichment {
+		person.Enrichment[attribute] = &models.AttributeEnrichment{Status: enrichment.Status}
+	}
+}
+
+func TestEnricher_Enrich(t *testing.T) {
 	tests := []struct {
 		name    string
-		fields  fields
-		args    args
+		person  *models.Person
+		prepare func(srv *enrichstub.Server)
 		want    *models.Person
 		wantErr bool
 	}{
 		{
-			fields: fields{
-				log:    logger.New("debug"),
-				client: &http.Client{},
+			name: "enriched",
+			person: &models.Person{
+				Name:       "Oleg",
+				Surname:    "Petrov",
+				Patronymic: "Ivanovich",
 			},
-			args: args{
-				person: &models.Person{
-					Name:       "Oleg",
-					Surname:    "Petrov",
-					Patronymic: "Ivanovich",
+			want: &models.Person{
+				Name:          "Oleg",
+				Surname:       "Petrov",
+				Patronymic:    "Ivanovich",
+				Age:           enrichstub.Age("Oleg"),
+				Gender:        "male",
+				Nationality:   enrichstub.Countries("Oleg")[0].CountryID,
+				Nationalities: stubNationalities("Oleg"),
+				Enrichment:    statuses(models.AttributeEnriched, models.AttributeEnriched, models.AttributeEnriched),
+			},
+		},
+		{
+			name:   "cyrillic name",
+			person: &models.Person{Name: "Анна", Surname: "Петрова"},
+			want: &models.Person{
+				Name:          "Анна",
+				Surname:       "Петрова",
+				Age:           enrichstub.Age("Анна"),
+				Gender:        "female",
+				Nationality:   enrichstub.Countries("Анна")[0].CountryID,
+				Nationalities: stubNationalities("Анна"),
+				Enrichment:    statuses(models.AttributeEnriched, models.AttributeEnriched, models.AttributeEnriched),
+			},
+		},
+		{
+			name:   "transient error retried",
+			person: &models.Person{Name: "Oleg", Surname: "Petrov"},
+			prepare: func(srv *enrichstub.Server) {
+				srv.FailNext(enrichstub.GenderizePath, http.StatusServiceUnavailable, 2)
+			},
+			want: &models.Person{
+				Name:          "Oleg",
+				Surname:       "Petrov",
+				Age:           enrichstub.Age("Oleg"),
+				Gender:        "male",
+				Nationality:   enrichstub.Countries("Oleg")[0].CountryID,
+				Nationalities: stubNationalities("Oleg"),
+				Enrichment:    statuses(models.AttributeEnriched, models.AttributeEnriched, models.AttributeEnriched),
+			},
+		},
+		{
+			name:   "provider error",
+			person: &models.Person{Name: "Oleg", Surname: "Petrov"},
+			prepare: func(srv *enrichstub.Server) {
+				srv.FailNext(enrichstub.GenderizePath, http.StatusInternalServerError, 3)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "client error is not retried",
+			person: &models.Person{Name: "Oleg", Surname: "Petrov"},
+			prepare: func(srv *enrichstub.Server) {
+				srv.FailNext(enrichstub.GenderizePath, http.StatusUnauthorized, 1)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "rate limited",
+			person: &models.Person{Name: "Oleg", Surname: "Petrov"},
+			prepare: func(srv *enrichstub.Server) {
+				srv.FailNext(enrichstub.AgifyPath, http.StatusTooManyRequests, 1)
+			},
+			want: &models.Person{
+				Name:          "Oleg",
+				Surname:       "Petrov",
+				Age:           enrichstub.Age("Oleg"),
+				Gender:        "male",
+				Nationality:   enrichstub.Countries("Oleg")[0].CountryID,
+				Nationalities: stubNationalities("Oleg"),
+				Enrichment:    statuses(models.AttributeEnriched, models.AttributeEnriched, models.AttributeEnriched),
+			},
+		},
+		{
+			name:   "unknown name",
+			person: &models.Person{Name: enrichstub.Unknown, Surname: "Petrov"},
+			want: &models.Person{
+				Name:       enrichstub.Unknown,
+				Surname:    "Petrov",
+				Enrichment: statuses(models.AttributeNotFound, models.AttributeNotFound, models.AttributeNotFound),
+			},
+		},
+		{
+			name:   "only empty attributes",
+			person: &models.Person{Name: "Oleg", Surname: "Petrov", Age: 33, Gender: "male"},
+			want: &models.Person{
+				Name:          "Oleg",
+				Surname:       "Petrov",
+				Age:           33,
+				Gender:        "male",
+				Nationality:   enrichstub.Countries("Oleg")[0].CountryID,
+				Nationalities: stubNationalities("Oleg"),
+				Enrichment: map[string]*models.AttributeEnrichment{
+					string(AttributeNationality): {Status: models.AttributeEnriched},
 				},
-				ctx: context.TODO(),
 			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := enrichstub.Start(enrichstub.Options{})
+			defer srv.Close()
+
+			if tt.prepare != nil {
+				tt.prepare(srv)
+			}
+
+			e := newTestEnricher(t, srv)
+
+			got, err := e.Enrich(context.Background(), tt.person)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Enricher.Enrich() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			onlyStatuses(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Enricher.Enrich() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnricher_EnrichQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RateLimitPolicy
+		want    *models.Person
+		wantErr bool
+	}{
+		{
+			name:   "degrade",
+			policy: RateLimitDegrade,
 			want: &models.Person{
-				Name:        "Oleg",
-				Surname:     "Petrov",
-				Patronymic:  "Ivanovich",
-				Age:         55,
-				Gender:      "male",
-				Nationality: "UA",
+				Name:       "Oleg",
+				Surname:    "Petrov",
+				Enrichment: statuses(models.AttributeFailed, models.AttributeFailed, models.AttributeFailed),
 			},
 		},
+		{
+			name:    "queue longer than max wait",
+			policy:  RateLimitQueue,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			e := &Enricher{
-				log:    tt.fields.log,
-				client: tt.fields.client,
+			srv := enrichstub.Start(enrichstub.Options{Quota: 1, QuotaWindow: time.Hour})
+			defer srv.Close()
+
+			log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			e, err := New(log, Config{
+				Providers:   []string{AgifyName, GenderizeName, NationalizeName},
+				Agify:       ProviderConfig{BaseURL: srv.AgifyURL(), Timeout: time.Second},
+				Genderize:   ProviderConfig{BaseURL: srv.GenderizeURL(), Timeout: time.Second},
+				Nationalize: ProviderConfig{BaseURL: srv.NationalizeURL(), Timeout: time.Second},
+				RateLimit:   RateLimitConfig{Policy: tt.policy, MaxWait: time.Second},
+			}, nil)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
 			}
-			got, err := e.Enrich(tt.args.ctx, tt.args.person)
+
+			// квота провайдеров выбрана
+			if _, err := e.Enrich(context.Background(), &models.Person{Name: "Anna"}); err != nil {
+				t.Fatalf("Enricher.Enrich() error = %v", err)
+			}
+
+			got, err := e.Enrich(context.Background(), &models.Person{Name: "Oleg", Surname: "Petrov"})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Enricher.Enrich() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			onlyStatuses(got)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Enricher.Enrich() = %v, want %v", got, tt.want)
 			}
+			if n := srv.Requests(enrichstub.AgifyPath); n != 1 {
+				t.Errorf("agify requests = %d, want 1", n)
+			}
+		})
+	}
+}
+
+type blockingProvider struct {
+	stubProvider
+	canceled chan struct{}
+}
+
+func (p blockingProvider) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	<-ctx.Done()
+	close(p.canceled)
+
+	return nil, ctx.Err()
+}
+
+type failingProvider struct {
+	stubProvider
+}
+
+func (p failingProvider) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	return nil, errors.New("invalid api key")
+}
+
+func TestEnricher_EnrichCancelsSiblings(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	blocking := blockingProvider{
+		stubProvider: stubProvider{name: "slow", attribute: AttributeAge},
+		canceled:     make(chan struct{}),
+	}
+
+	e := NewWithRegistry(log, NewRegistry(
+		blocking,
+		failingProvider{stubProvider{name: "broken", attribute: AttributeGender}},
+	))
+
+	if _, err := e.Enrich(context.Background(), &models.Person{Name: "Oleg"}); err == nil {
+		t.Fatalf("Enricher.Enrich() error = nil, want error")
+	}
+
+	select {
+	case <-blocking.canceled:
+	case <-time.After(time.Second):
+		t.Errorf("sibling fetch is not canceled")
+	}
+}
+
+func TestEnricher_EnrichDeadline(t *testing.T) {
+	srv := enrichstub.Start(enrichstub.Options{Latency: 5 * time.Second})
+	defer srv.Close()
+
+	e := newTestEnricher(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := e.Enrich(ctx, &models.Person{Name: "Oleg"}); err == nil {
+		t.Fatalf("Enricher.Enrich() error = nil, want error")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Enricher.Enrich() took %v after deadline of request", elapsed)
+	}
+}
+
+func TestEnricher_EnrichPartial(t *testing.T) {
+	srv := enrichstub.Start(enrichstub.Options{})
+	defer srv.Close()
+
+	srv.FailNext(enrichstub.GenderizePath, http.StatusInternalServerError, 3)
+
+	e := newTestEnricher(t, srv, func(cfg *Config) { cfg.Partial = true })
+
+	got, err := e.Enrich(context.Background(), &models.Person{Name: "Oleg", Surname: "Petrov"})
+	if err != nil {
+		t.Fatalf("Enricher.Enrich() error = %v", err)
+	}
+
+	if got.Enrichment[string(AttributeGender)].Error == "" {
+		t.Errorf("error of failed attribute is empty")
+	}
+	onlyStatuses(got)
+
+	want := &models.Person{
+		Name:          "Oleg",
+		Surname:       "Petrov",
+		Age:           enrichstub.Age("Oleg"),
+		Nationality:   enrichstub.Countries("Oleg")[0].CountryID,
+		Nationalities: stubNationalities("Oleg"),
+		Enrichment:    statuses(models.AttributeEnriched, models.AttributeFailed, models.AttributeEnriched),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Enricher.Enrich() = %v, want %v", got, want)
+	}
+	if !got.EnrichmentFailed() {
+		t.Errorf("EnrichmentFailed() = false, want true")
+	}
+
+	// повтор дообогащает только пол
+	got, err = e.Enrich(context.Background(), got)
+	if err != nil {
+		t.Fatalf("Enricher.Enrich() retry error = %v", err)
+	}
+	if got.Gender != "male" || got.EnrichmentFailed() {
+		t.Errorf("Enricher.Enrich() retry = %v", got)
+	}
+	if n := srv.Requests(enrichstub.AgifyPath); n != 1 {
+		t.Errorf("agify requests = %d, want 1", n)
+	}
+}
+
+func TestEnricher_EnrichProvenance(t *testing.T) {
+	srv := enrichstub.Start(enrichstub.Options{})
+	defer srv.Close()
+
+	e := newTestEnricher(t, srv)
+
+	got, err := e.Enrich(context.Background(), &models.Person{Name: "Anna", Surname: "Petrova"})
+	if err != nil {
+		t.Fatalf("Enricher.Enrich() error = %v", err)
+	}
+
+	country := enrichstub.Countries("Anna")[0]
+
+	want := map[string]*models.AttributeEnrichment{
+		string(AttributeAge): {
+			Status:   models.AttributeEnriched,
+			Value:    strconv.Itoa(enrichstub.Age("Anna")),
+			Count:    enrichstub.Count("Anna"),
+			Provider: AgifyName,
+		},
+		string(AttributeGender): {
+			Status:      models.AttributeEnriched,
+			Value:       "female",
+			Probability: enrichstub.GenderProbability("Anna"),
+			Count:       enrichstub.Count("Anna"),
+			Provider:    GenderizeName,
+		},
+		string(AttributeNationality): {
+			Status:      models.AttributeEnriched,
+			Value:       country.CountryID,
+			Probability: country.Probability,
+			Count:       enrichstub.Count("Anna"),
+			Provider:    NationalizeName,
+		},
+	}
+
+	for attribute, enrichment := range got.Enrichment {
+		if enrichment.FetchedAt.IsZero() {
+			t.Errorf("%s fetched at is zero", attribute)
+		}
+		enrichment.FetchedAt = time.Time{}
+	}
+
+	if !reflect.DeepEqual(got.Enrichment, want) {
+		t.Errorf("Enrichment = %v, want %v", got.Enrichment, want)
+	}
+}
+
+func TestEnricher_EnrichThreshold(t *testing.T) {
+	srv := enrichstub.Start(enrichstub.Options{})
+	defer srv.Close()
+
+	e := newTestEnricher(t, srv, func(cfg *Config) {
+		cfg.Thresholds = map[Attribute]Threshold{
+			AttributeAge:         {MinCount: enrichstub.Count("Oleg")},
+			AttributeGender:      {MinProbability: enrichstub.GenderProbability("Oleg") + 0.01},
+			AttributeNationality: {MinCount: enrichstub.Count("Oleg") + 1},
+		}
+	})
+
+	got, err := e.Enrich(context.Background(), &models.Person{Name: "Oleg", Surname: "Petrov"})
+	if err != nil {
+		t.Fatalf("Enricher.Enrich() error = %v", err)
+	}
+
+	if !got.NeedsReview() {
+		t.Errorf("NeedsReview() = false, want true")
+	}
+
+	// rejected values survive for review, fields stay empty
+	rejected := map[Attribute]models.AttributeEnrichment{
+		AttributeGender: {
+			Status:      models.AttributeUnknown,
+			Value:       enrichstub.Gender("Oleg"),
+			Probability: enrichstub.GenderProbability("Oleg"),
+			Count:       enrichstub.Count("Oleg"),
+			Provider:    GenderizeName,
+		},
+		AttributeNationality: {
+			Status:      models.AttributeUnknown,
+			Value:       enrichstub.Countries("Oleg")[0].CountryID,
+			Probability: enrichstub.Countries("Oleg")[0].Probability,
+			Count:       enrichstub.Count("Oleg"),
+			Provider:    NationalizeName,
+		},
+	}
+	for attribute, want := range rejected {
+		enrichment := *got.Enrichment[string(attribute)]
+		enrichment.FetchedAt = time.Time{}
+
+		if enrichment != want {
+			t.Errorf("unknown %s enrichment = %+v, want %+v", attribute, enrichment, want)
+		}
+	}
+
+	onlyStatuses(got)
+
+	want := &models.Person{
+		Name:       "Oleg",
+		Surname:    "Petrov",
+		Age:        enrichstub.Age("Oleg"),
+		Enrichment: statuses(models.AttributeEnriched, models.AttributeUnknown, models.AttributeUnknown),
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Enricher.Enrich() = %v, want %v", got, want)
+	}
+}
+
+func TestEnricher_EnrichLocalized(t *testing.T) {
+	srv := enrichstub.Start(enrichstub.Options{})
+	defer srv.Close()
+
+	country := enrichstub.Countries("Oleg")[0].CountryID
+
+	tests := []struct {
+		name      string
+		localized bool
+		hint      string
+		wantAge   int
+		wantProb  float64
+	}{
+		{
+			name:      "by nationality",
+			localized: true,
+			wantAge:   enrichstub.AgeIn("Oleg", country),
+			wantProb:  enrichstub.GenderProbabilityIn("Oleg", country),
+		},
+		{
+			name:      "by hint",
+			localized: true,
+			hint:      "US",
+			wantAge:   enrichstub.AgeIn("Oleg", "US"),
+			wantProb:  enrichstub.GenderProbabilityIn("Oleg", "US"),
+		},
+		{
+			name:     "parallel",
+			wantAge:  enrichstub.Age("Oleg"),
+			wantProb: enrichstub.GenderProbability("Oleg"),
+		},
+		{
+			name:     "parallel by hint",
+			hint:     "US",
+			wantAge:  enrichstub.AgeIn("Oleg", "US"),
+			wantProb: enrichstub.GenderProbabilityIn("Oleg", "US"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnricher(t, srv, func(cfg *Config) {
+				cfg.Localized = tt.localized
+			})
+
+			got, err := e.Enrich(context.Background(), &models.Person{Name: "Oleg", Surname: "Petrov", CountryHint: tt.hint})
+			if err != nil {
+				t.Fatalf("Enricher.Enrich() error = %v", err)
+			}
+
+			if got.Age != tt.wantAge {
+				t.Errorf("Age = %d, want %d", got.Age, tt.wantAge)
+			}
+			if p := got.Enrichment[string(AttributeGender)].Probability; p != tt.wantProb {
+				t.Errorf("gender probability = %v, want %v", p, tt.wantProb)
+			}
+			if got.Nationality != country {
+				t.Errorf("Nationality = %q, want %q", got.Nationality, country)
+			}
 		})
 	}
 }
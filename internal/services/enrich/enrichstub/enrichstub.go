@@ -0,0 +1,391 @@
+// Package enrichstub emulates agify.io, genderize.io and nationalize.io
+// for tests and local runs. Answers are deterministic for a name and country_id.
+package enrichstub
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	AgifyPath       = "/agify/"
+	GenderizePath   = "/genderize/"
+	NationalizePath = "/nationalize/"
+
+	// Unknown - name the APIs know nothing about: null age and gender, empty countries
+	Unknown = "unknown"
+
+	// MaxBatch - names in one name[] request
+	MaxBatch = 10
+
+	RateLimitLimitHeader     = "X-Rate-Limit-Limit"
+	RateLimitRemainingHeader = "X-Rate-Limit-Remaining"
+	RateLimitResetHeader     = "X-Rate-Limit-Reset"
+)
+
+var countries = []string{"RU", "UA", "BY", "KZ", "PL", "DE", "US", "GB", "FR", "IT"}
+
+// Options - behaviour of stub. Rates are shares of requests in [0, 1].
+// Quota > 0 limits requests to every API per QuotaWindow (24h if zero)
+// and adds X-Rate-Limit-* headers to answers
+type Options struct {
+	Latency       time.Duration
+	ErrorRate     float64
+	RateLimitRate float64
+	Seed          int64
+	Quota         int
+	QuotaWindow   time.Duration
+}
+
+// Stub - http.Handler serving the three APIs under AgifyPath, GenderizePath and NationalizePath.
+// Like the APIs, it answers array to up to MaxBatch name[] parameters, every name takes quota
+type Stub struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	failures map[string][]int
+	requests map[string]int
+
+	windowStart time.Time
+	used        map[string]int
+}
+
+func New(opts Options) *Stub {
+	s := &Stub{
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		rand:     rand.New(rand.NewSource(opts.Seed)),
+		failures: make(map[string][]int),
+		requests: make(map[string]int),
+
+		windowStart: time.Now(),
+		used:        make(map[string]int),
+	}
+
+	if s.opts.QuotaWindow <= 0 {
+		s.opts.QuotaWindow = 24 * time.Hour
+	}
+
+	s.mux.HandleFunc(AgifyPath, s.handle(AgifyPath, s.agify))
+	s.mux.HandleFunc(GenderizePath, s.handle(GenderizePath, s.genderize))
+	s.mux.HandleFunc(NationalizePath, s.handle(NationalizePath, s.nationalize))
+
+	return s
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// FailNext makes next n requests to path answer with status
+func (s *Stub) FailNext(path string, status int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.failures[path] = append(s.failures[path], status)
+	}
+}
+
+// Requests returns number of requests served on path
+func (s *Stub) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+// Server - running stub
+type Server struct {
+	*Stub
+	*httptest.Server
+}
+
+// Start runs stub on random local port, Close it after use
+func Start(opts Options) *Server {
+	stub := New(opts)
+
+	return &Server{Stub: stub, Server: httptest.NewServer(stub)}
+}
+
+func (s *Server) AgifyURL() string       { return s.URL + AgifyPath }
+func (s *Server) GenderizeURL() string   { return s.URL + GenderizePath }
+func (s *Server) NationalizeURL() string { return s.URL + NationalizePath }
+
+// Country - nationalize candidate
+type Country struct {
+	CountryID   string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+// Age returns age stub answers for name, 0 for Unknown
+func Age(name string) int {
+	return AgeIn(name, "")
+}
+
+// AgeIn returns age stub answers for name with country_id, Age if country is empty
+func AgeIn(name string, country string) int {
+	if isUnknown(name) {
+		return 0
+	}
+
+	return 18 + int(hash(name, localized("age", country))%70)
+}
+
+// Gender returns "female" for names ending with a/я, "male" otherwise, "" for Unknown
+func Gender(name string) string {
+	if isUnknown(name) {
+		return ""
+	}
+
+	last, _ := utf8.DecodeLastRuneInString(strings.ToLower(name))
+	if last == 'a' || last == 'а' || last == 'я' {
+		return "female"
+	}
+
+	return "male"
+}
+
+// GenderProbability returns probability of Gender answer
+func GenderProbability(name string) float64 {
+	return GenderProbabilityIn(name, "")
+}
+
+// GenderProbabilityIn returns probability of Gender answer with country_id
+func GenderProbabilityIn(name string, country string) float64 {
+	if isUnknown(name) {
+		return 0
+	}
+
+	return 0.5 + float64(hash(name, localized("gender", country))%50)/100
+}
+
+// Count returns number of samples of name
+func Count(name string) int {
+	if isUnknown(name) {
+		return 0
+	}
+
+	return 1 + int(hash(name, "count")%10000)
+}
+
+// Countries returns up to three candidates ordered by probability
+func Countries(name string) []Country {
+	if isUnknown(name) {
+		return []Country{}
+	}
+
+	h := hash(name, "country")
+	first := int(h % uint32(len(countries)))
+
+	result := make([]Country, 0, 3)
+	left := 1.0
+
+	for i := 0; i < 3; i++ {
+		probability := left * (0.5 + float64((h>>(i*4))%4)/10)
+		left -= probability
+
+		result = append(result, Country{
+			CountryID:   countries[(first+i*3)%len(countries)],
+			Probability: float64(int(probability*1000)) / 1000,
+		})
+	}
+
+	return result
+}
+
+func (s *Stub) handle(path string, answer func(name string, country string) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if s.opts.Latency > 0 {
+			select {
+			case <-time.After(s.opts.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		s.mu.Lock()
+		s.requests[path]++
+		s.mu.Unlock()
+
+		query := r.URL.Query()
+
+		names, batch := query["name[]"]
+		if !batch {
+			names = []string{query.Get("name")}
+		}
+
+		if s.opts.Quota > 0 {
+			remaining, reset := s.consume(path, len(names))
+
+			w.Header().Set(RateLimitLimitHeader, strconv.Itoa(s.opts.Quota))
+			w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(max(remaining, 0)))
+			w.Header().Set(RateLimitResetHeader, strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+
+			if remaining < 0 {
+				writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "Request limit reached"})
+
+				return
+			}
+		}
+
+		if status := s.failure(path); status != 0 {
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+
+			writeJSON(w, status, map[string]string{"error": http.StatusText(status)})
+
+			return
+		}
+
+		country := query.Get("country_id")
+
+		if batch {
+			if len(names) > MaxBatch {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Invalid 'name[]' parameter"})
+
+				return
+			}
+
+			answers := make([]any, 0, len(names))
+			for _, name := range names {
+				answers = append(answers, answer(name, country))
+			}
+
+			writeJSON(w, http.StatusOK, answers)
+
+			return
+		}
+
+		if names[0] == "" {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Missing 'name' parameter"})
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, answer(names[0], country))
+	}
+}
+
+// consume takes n names from quota of path. Negative remaining - quota is exhausted
+func (s *Stub) consume(path string, n int) (int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(s.windowStart) >= s.opts.QuotaWindow {
+		s.windowStart = now
+		s.used = make(map[string]int)
+	}
+
+	s.used[path] += n
+
+	return s.opts.Quota - s.used[path], s.windowStart.Add(s.opts.QuotaWindow).Sub(now)
+}
+
+// failure returns status to fail request with or 0
+func (s *Stub) failure(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if queue := s.failures[path]; len(queue) > 0 {
+		s.failures[path] = queue[1:]
+
+		return queue[0]
+	}
+
+	roll := s.rand.Float64()
+
+	switch {
+	case roll < s.opts.RateLimitRate:
+		return http.StatusTooManyRequests
+	case roll < s.opts.RateLimitRate+s.opts.ErrorRate:
+		return http.StatusInternalServerError
+	}
+
+	return 0
+}
+
+func (s *Stub) agify(name string, country string) any {
+	return struct {
+		Count     int    `json:"count"`
+		Name      string `json:"name"`
+		Age       *int   `json:"age"`
+		CountryID string `json:"country_id,omitempty"`
+	}{Count(name), name, nullable(AgeIn(name, country)), country}
+}
+
+func (s *Stub) genderize(name string, country string) any {
+	gender := Gender(name)
+
+	var genderPtr *string
+	if gender != "" {
+		genderPtr = &gender
+	}
+
+	return struct {
+		Count       int     `json:"count"`
+		Name        string  `json:"name"`
+		Gender      *string `json:"gender"`
+		Probability float64 `json:"probability"`
+		CountryID   string  `json:"country_id,omitempty"`
+	}{Count(name), name, genderPtr, GenderProbabilityIn(name, country), country}
+}
+
+// nationalize has no country_id parameter
+func (s *Stub) nationalize(name string, _ string) any {
+	return struct {
+		Count   int       `json:"count"`
+		Name    string    `json:"name"`
+		Country []Country `json:"country"`
+	}{Count(name), name, Countries(name)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func nullable(v int) *int {
+	if v == 0 {
+		return nil
+	}
+
+	return &v
+}
+
+func isUnknown(name string) bool {
+	return strings.EqualFold(name, Unknown)
+}
+
+// localized - salt of answer in country
+func localized(salt string, country string) string {
+	if country == "" {
+		return salt
+	}
+
+	return salt + ":" + strings.ToUpper(country)
+}
+
+func hash(name string, salt string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(name) + ":" + salt))
+
+	return h.Sum32()
+}
@@ -0,0 +1,148 @@
+package enrichstub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStub(t *testing.T) {
+	srv := Start(Options{})
+	defer srv.Close()
+
+	var got struct {
+		Age   int `json:"age"`
+		Count int `json:"count"`
+	}
+
+	get := func(path string, name string, target any) int {
+		resp, err := http.Get(srv.URL + path + "?name=" + url.QueryEscape(name))
+		if err != nil {
+			t.Fatalf("http.Get() error = %v", err)
+		}
+		defer resp.Body.Close()
+
+		if target != nil {
+			if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+		}
+
+		return resp.StatusCode
+	}
+
+	if status := get(AgifyPath, "Oleg", &got); status != http.StatusOK || got.Age != Age("Oleg") || got.Count != Count("Oleg") {
+		t.Errorf("agify = %d %+v, want age %d", status, got, Age("Oleg"))
+	}
+
+	srv.FailNext(AgifyPath, http.StatusTooManyRequests, 2)
+
+	for i := 0; i < 2; i++ {
+		if status := get(AgifyPath, "Oleg", nil); status != http.StatusTooManyRequests {
+			t.Errorf("status = %d, want %d", status, http.StatusTooManyRequests)
+		}
+	}
+
+	if status := get(AgifyPath, "Oleg", nil); status != http.StatusOK {
+		t.Errorf("status after failures = %d, want %d", status, http.StatusOK)
+	}
+
+	if n := srv.Requests(AgifyPath); n != 4 {
+		t.Errorf("Requests() = %d, want 4", n)
+	}
+
+	var local struct {
+		Age       int    `json:"age"`
+		CountryID string `json:"country_id"`
+	}
+
+	resp, err := http.Get(srv.AgifyURL() + "?name=Oleg&country_id=US")
+	if err != nil {
+		t.Fatalf("http.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&local); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if local.Age != AgeIn("Oleg", "US") || local.CountryID != "US" {
+		t.Errorf("localized agify = %+v, want age %d in US", local, AgeIn("Oleg", "US"))
+	}
+
+	var batch []struct {
+		Name string `json:"name"`
+		Age  *int   `json:"age"`
+	}
+
+	resp, err = http.Get(srv.AgifyURL() + "?name[]=Oleg&name[]=" + Unknown)
+	if err != nil {
+		t.Fatalf("http.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(batch) != 2 || batch[0].Name != "Oleg" || batch[0].Age == nil || *batch[0].Age != Age("Oleg") || batch[1].Age != nil {
+		t.Errorf("batch agify = %+v, want Oleg and unknown", batch)
+	}
+
+	var nationality struct {
+		Country []Country `json:"country"`
+	}
+
+	if get(NationalizePath, Unknown, &nationality); len(nationality.Country) != 0 {
+		t.Errorf("countries of unknown = %v, want empty", nationality.Country)
+	}
+}
+
+func TestStub_Quota(t *testing.T) {
+	srv := Start(Options{Quota: 2, QuotaWindow: time.Hour})
+	defer srv.Close()
+
+	for i, want := range []struct {
+		status    int
+		remaining string
+	}{
+		{http.StatusOK, "1"},
+		{http.StatusOK, "0"},
+		{http.StatusTooManyRequests, "0"},
+	} {
+		resp, err := http.Get(srv.GenderizeURL() + "?name=Oleg")
+		if err != nil {
+			t.Fatalf("http.Get() error = %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != want.status || resp.Header.Get(RateLimitRemainingHeader) != want.remaining {
+			t.Errorf("request %d = %d remaining %q, want %d remaining %q",
+				i, resp.StatusCode, resp.Header.Get(RateLimitRemainingHeader), want.status, want.remaining)
+		}
+
+		if reset, _ := strconv.Atoi(resp.Header.Get(RateLimitResetHeader)); reset < 1 || reset > 3600 {
+			t.Errorf("reset = %q, want (0, 3600]", resp.Header.Get(RateLimitResetHeader))
+		}
+	}
+
+	if n := srv.Requests(GenderizePath); n != 3 {
+		t.Errorf("Requests() = %d, want 3", n)
+	}
+}
+
+func TestCountries(t *testing.T) {
+	for _, name := range []string{"Oleg", "Анна", "Ivan", "x"} {
+		got := Countries(name)
+		if len(got) != 3 {
+			t.Fatalf("Countries(%s) len = %d, want 3", name, len(got))
+		}
+
+		for i := 1; i < len(got); i++ {
+			if got[i].Probability > got[i-1].Probability {
+				t.Errorf("Countries(%s) not ordered: %v", name, got)
+			}
+		}
+	}
+}
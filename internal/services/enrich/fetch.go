@@ -3,120 +3,353 @@ package enrich
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"sync"
+	"net/url"
+	"strconv"
+	"test-task/internal/domain/models"
+	"time"
 )
 
-func (e *Enricher) fetchAge(ctx context.Context, name string, wg *sync.WaitGroup, resultChan chan<- int, errChan chan<- error) {
+const (
+	AgifyName       = "agify"
+	GenderizeName   = "genderize"
+	NationalizeName = "nationalize"
+)
+
+// Builtin returns agify, genderize and nationalize providers configured by cfg.
+// Every provider has own quota of cfg.RateLimit
+func Builtin(log *slog.Logger, cfg Config) []Provider {
+	rateLimit := cfg.RateLimit.withDefaults()
+
+	return []Provider{
+		&Agify{api: newAPIClient(log, AgifyName, cfg.Agify.withDefaults(DefaultAgifyURL), rateLimit)},
+		&Genderize{api: newAPIClient(log, GenderizeName, cfg.Genderize.withDefaults(DefaultGenderizeURL), rateLimit)},
+		&Nationalize{api: newAPIClient(log, NationalizeName, cfg.Nationalize.withDefaults(DefaultNationalizeURL), rateLimit)},
+	}
+}
+
+// Agify - age by name from agify.io
+type Agify struct {
+	api *apiClient
+}
+
+func (p *Agify) Name() string         { return AgifyName }
+func (p *Agify) Attribute() Attribute { return AttributeAge }
+
+// agifyAnswer - answer of agify for one name
+type agifyAnswer struct {
+	Age   int `json:"age"`
+	Count int `json:"count"`
+}
 
-	defer wg.Done()
-	url := AgeAPI + name
-	var result struct {
-		Age int `json:"age"`
+// result - null age means name is unknown
+func (a agifyAnswer) result() *Result {
+	if a.Age == 0 {
+		return &Result{Count: a.Count}
 	}
 
-	e.log.Debug("request to fetch age", "name", name, "url", url)
+	return &Result{Value: strconv.Itoa(a.Age), Count: a.Count}
+}
 
-	if err := e.fetchAPI(url, &result); err != nil {
-		e.log.Error("failed to fetch age", "error", err, "name", name)
-		errChan <- fmt.Errorf("error age API: %w", err)
-		return
+func (p *Agify) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	url, err := p.api.url(name, country)
+	if err != nil {
+		return nil, err
 	}
+	var result agifyAnswer
 
-	e.log.Debug("fetched gender", "result", result.Age)
+	p.api.log.Debug("request to fetch age", "name", name, "country", country, "provider", p.Name())
 
-	resultChan <- result.Age
+	if err := p.api.fetch(ctx, url, 1, &result); err != nil {
+		p.api.log.Error("failed to fetch age", "error", err, "name", name)
+		return nil, fmt.Errorf("error age API: %w", err)
+	}
+
+	p.api.log.Debug("fetched age", "result", result.Age)
+
+	return result.result(), nil
 }
 
-func (e *Enricher) fetchGender(ctx context.Context, name string, wg *sync.WaitGroup, resultChan chan<- string, errChan chan<- error) {
-	defer wg.Done()
-	url := GenderAPI + name
-	var result struct {
-		Gender string `json:"gender"`
+func (p *Agify) FetchBatch(ctx context.Context, names []string, country string) ([]*Result, error) {
+	p.api.log.Debug("request to fetch ages", "names", len(names), "country", country, "provider", p.Name())
+
+	answers, err := fetchBatch[agifyAnswer](ctx, p.api, names, country)
+	if err != nil {
+		p.api.log.Error("failed to fetch ages", "error", err, "names", len(names))
+		return nil, fmt.Errorf("error age API: %w", err)
 	}
 
-	e.log.Debug("request to fetch gender", "name", name, "url", url)
+	results := make([]*Result, 0, len(answers))
+	for _, answer := range answers {
+		results = append(results, answer.result())
+	}
+
+	return results, nil
+}
+
+// Genderize - gender by name from genderize.io
+type Genderize struct {
+	api *apiClient
+}
+
+func (p *Genderize) Name() string         { return GenderizeName }
+func (p *Genderize) Attribute() Attribute { return AttributeGender }
 
-	if err := e.fetchAPI(url, &result); err != nil {
-		e.log.Error("failed to fetch age", "error", err, "name", name)
-		errChan <- fmt.Errorf("error age API err: %w", err)
-		return
+// genderizeAnswer - answer of genderize for one name
+type genderizeAnswer struct {
+	Gender      string  `json:"gender"`
+	Probability float64 `json:"probability"`
+	Count       int     `json:"count"`
+}
+
+func (a genderizeAnswer) result() *Result {
+	return &Result{Value: a.Gender, Probability: a.Probability, Count: a.Count}
+}
+
+func (p *Genderize) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	url, err := p.api.url(name, country)
+	if err != nil {
+		return nil, err
+	}
+	var result genderizeAnswer
+
+	p.api.log.Debug("request to fetch gender", "name", name, "country", country, "provider", p.Name())
+
+	if err := p.api.fetch(ctx, url, 1, &result); err != nil {
+		p.api.log.Error("failed to fetch gender", "error", err, "name", name)
+		return nil, fmt.Errorf("error gender API err: %w", err)
+	}
+
+	p.api.log.Debug("fetched gender", "result", result.Gender)
+
+	return result.result(), nil
+}
+
+func (p *Genderize) FetchBatch(ctx context.Context, names []string, country string) ([]*Result, error) {
+	p.api.log.Debug("request to fetch genders", "names", len(names), "country", country, "provider", p.Name())
+
+	answers, err := fetchBatch[genderizeAnswer](ctx, p.api, names, country)
+	if err != nil {
+		p.api.log.Error("failed to fetch genders", "error", err, "names", len(names))
+		return nil, fmt.Errorf("error gender API err: %w", err)
 	}
 
-	e.log.Debug("fetched gender", "result", result.Gender)
+	results := make([]*Result, 0, len(answers))
+	for _, answer := range answers {
+		results = append(results, answer.result())
+	}
 
-	resultChan <- result.Gender
+	return results, nil
 }
 
-func (e *Enricher) fetchNationality(ctx context.Context, name string, wg *sync.WaitGroup, resultChan chan<- string, errChan chan<- error) {
-	defer wg.Done()
-	url := NationalityAPI + name
-	type countryEntity struct {
+// Nationalize - nationality by name from nationalize.io, other countries are candidates
+type Nationalize struct {
+	api *apiClient
+}
+
+func (p *Nationalize) Name() string         { return NationalizeName }
+func (p *Nationalize) Attribute() Attribute { return AttributeNationality }
+
+// nationalizeAnswer - answer of nationalize for one name
+type nationalizeAnswer struct {
+	Count     int `json:"count"`
+	Countries []struct {
 		CountryID   string  `json:"country_id"`
 		Probability float64 `json:"probability"`
+	} `json:"country"`
+}
+
+// result - empty countries means name is unknown
+func (a nationalizeAnswer) result() *Result {
+	if len(a.Countries) < 1 {
+		return &Result{Count: a.Count}
 	}
 
-	var result struct {
-		Countries []countryEntity `json:"country"`
+	candidates := make([]models.Candidate, 0, len(a.Countries))
+	for _, country := range a.Countries {
+		candidates = append(candidates, models.Candidate{Value: country.CountryID, Probability: country.Probability})
 	}
 
-	e.log.Debug("request to fetch nationality", "name", name, "url", url)
+	return &Result{
+		Value:       a.Countries[0].CountryID,
+		Probability: a.Countries[0].Probability,
+		Count:       a.Count,
+		Candidates:  candidates,
+	}
+}
+
+// Fetch ignores country, nationality is not localized
+func (p *Nationalize) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	url, err := p.api.url(name, "")
+	if err != nil {
+		return nil, err
+	}
+	var result nationalizeAnswer
+
+	p.api.log.Debug("request to fetch nationality", "name", name, "provider", p.Name())
 
-	if err := e.fetchAPI(url, &result); err != nil {
-		e.log.Error("failed to fetch nationality", "error", err, "name", name)
-		errChan <- fmt.Errorf("error nationality API err: %w", err)
-		return
+	if err := p.api.fetch(ctx, url, 1, &result); err != nil {
+		p.api.log.Error("failed to fetch nationality", "error", err, "name", name)
+		return nil, fmt.Errorf("error nationality API err: %w", err)
 	}
 
 	if len(result.Countries) < 1 {
-		err := errors.New("len of nationality less 1")
+		p.api.log.Debug("nationality is unknown", "name", name)
+	} else {
+		p.api.log.Debug("fetched nationality", "result", result.Countries[0].CountryID, "candidates", len(result.Countries))
+	}
+
+	return result.result(), nil
+}
+
+// FetchBatch ignores country, nationality is not localized
+func (p *Nationalize) FetchBatch(ctx context.Context, names []string, country string) ([]*Result, error) {
+	p.api.log.Debug("request to fetch nationalities", "names", len(names), "provider", p.Name())
+
+	answers, err := fetchBatch[nationalizeAnswer](ctx, p.api, names, "")
+	if err != nil {
+		p.api.log.Error("failed to fetch nationalities", "error", err, "names", len(names))
+		return nil, fmt.Errorf("error nationality API err: %w", err)
+	}
+
+	results := make([]*Result, 0, len(answers))
+	for _, answer := range answers {
+		results = append(results, answer.result())
+	}
+
+	return results, nil
+}
+
+type apiClient struct {
+	client  *http.Client
+	timeout time.Duration
+	log     *slog.Logger
+	name    string
+	baseURL string
+	apiKey  string
+	retry   RetryConfig
+
+	rateLimit RateLimitConfig
+	limiter   *tokenBucket
+}
+
+func newAPIClient(log *slog.Logger, name string, cfg ProviderConfig, rateLimit RateLimitConfig) *apiClient {
+	return &apiClient{
+		client:    &http.Client{},
+		timeout:   cfg.Timeout,
+		log:       log,
+		name:      name,
+		baseURL:   cfg.BaseURL,
+		apiKey:    cfg.APIKey,
+		retry:     cfg.Retry,
+		rateLimit: rateLimit,
+		limiter:   newTokenBucket(rateLimit.Limit, rateLimit.Period),
+	}
+}
+
+// url builds request url with escaped name, country and api key if they're set
+func (a *apiClient) url(name string, country string) (string, error) {
+	return a.query(url.Values{"name": {name}}, country)
+}
+
+// batchURL builds request url of many names as name[] parameters
+func (a *apiClient) batchURL(names []string, country string) (string, error) {
+	return a.query(url.Values{"name[]": names}, country)
+}
+
+func (a *apiClient) query(names url.Values, country string) (string, error) {
+	u, err := url.Parse(a.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %q:%w", a.baseURL, err)
+	}
+
+	query := u.Query()
+	for key, values := range names {
+		query[key] = values
+	}
+	if country != "" {
+		query.Set("country_id", country)
+	}
+	if a.apiKey != "" {
+		query.Set("apikey", a.apiKey)
+	}
+
+	u.RawQuery = query.Encode()
 
-		e.log.Error("failed to fetch nationality", "error", err, "name", name)
-		errChan <- fmt.Errorf("error nationality API err: %w", err)
-		return
+	return u.String(), nil
+}
+
+// fetchBatch fetches answers of names in one request, answers are in order of names
+func fetchBatch[T any](ctx context.Context, a *apiClient, names []string, country string) ([]T, error) {
+	url, err := a.batchURL(names, country)
+	if err != nil {
+		return nil, err
 	}
 
-	e.log.Debug("fetched nationality", "result", result.Countries[0].CountryID)
+	var answers []T
 
-	resultChan <- result.Countries[0].CountryID
+	if err := a.fetch(ctx, url, len(names), &answers); err != nil {
+		return nil, err
+	}
+
+	if len(answers) != len(names) {
+		return nil, fmt.Errorf("%d answers for %d names", len(answers), len(names))
+	}
+
+	return answers, nil
 }
 
-func (e *Enricher) fetchAPI(url string, target interface{}) error {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// fetchAPI makes one attempt. Its deadline is provider timeout or deadline of ctx if it's earlier
+func (a *apiClient) fetchAPI(ctx context.Context, url string, target interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		e.log.Error("can't get request")
+		a.log.Error("can't get request")
 
 		return fmt.Errorf("can't get request:%w", err)
 	}
 
-	resp, err := e.client.Do(req)
+	resp, err := a.client.Do(req)
 	if err != nil {
-		e.log.Error("can't get request")
+		a.log.Error("can't get request")
 
-		return fmt.Errorf("request failed: %w", err)
+		return &requestError{err: err}
 
 	}
 	defer resp.Body.Close()
 
+	a.limiter.observeHeaders(resp.Header)
+
 	if resp.StatusCode != http.StatusOK {
-		e.log.Error("can't get request")
+		a.log.Error("can't get request")
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			a.limiter.observe(0, retryAfter)
+		}
 
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: retryAfter,
+		}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		e.log.Error("can't get request")
+		a.log.Error("can't get request")
 
 		return fmt.Errorf("read body failed: %w", err)
 
 	}
 
 	if err := json.Unmarshal(body, target); err != nil {
-		e.log.Error("can't get request")
+		a.log.Error("can't get request")
 
 		return fmt.Errorf("json unmarshal failed: %w", err)
 
@@ -0,0 +1,63 @@
+package enrich
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func Test_apiClient_url(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ProviderConfig
+		person  string
+		country string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "default",
+			cfg:    ProviderConfig{}.withDefaults(DefaultAgifyURL),
+			person: "Oleg",
+			want:   "https://api.agify.io/?name=Oleg",
+		},
+		{
+			name:   "space and cyrillic",
+			cfg:    ProviderConfig{BaseURL: "http://localhost:8090/agify"},
+			person: "Анна Мария",
+			want:   "http://localhost:8090/agify?name=%D0%90%D0%BD%D0%BD%D0%B0+%D0%9C%D0%B0%D1%80%D0%B8%D1%8F",
+		},
+		{
+			name:   "injection and api key",
+			cfg:    ProviderConfig{BaseURL: "https://api.genderize.io/", APIKey: "secret"},
+			person: "oleg&country_id=US",
+			want:   "https://api.genderize.io/?apikey=secret&name=oleg%26country_id%3DUS",
+		},
+		{
+			name:    "country",
+			cfg:     ProviderConfig{}.withDefaults(DefaultGenderizeURL),
+			person:  "Oleg",
+			country: "RU",
+			want:    "https://api.genderize.io/?country_id=RU&name=Oleg",
+		},
+		{
+			name:    "invalid base url",
+			cfg:     ProviderConfig{BaseURL: "http://[::1"},
+			person:  "Oleg",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAPIClient(slog.Default(), AgifyName, tt.cfg, RateLimitConfig{})
+
+			got, err := a.url(tt.person, tt.country)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("apiClient.url() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("apiClient.url() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
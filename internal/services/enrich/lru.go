@@ -0,0 +1,83 @@
+package enrich
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru - in-memory cache with limited size and ttl of entries
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	result    Result
+	expiresAt time.Time
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *lru) Get(key string) (*Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*lruEntry)
+
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+
+	result := entry.result
+
+	return &result, true
+}
+
+// Add stores result fetched at fetchedAt, so entries loaded from db expire in time
+func (c *lru) Add(key string, result Result, fetchedAt time.Time) {
+	if c.size < 1 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := fetchedAt.Add(c.ttl)
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = &lruEntry{key: key, result: result, expiresAt: expiresAt}
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, result: result, expiresAt: expiresAt})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
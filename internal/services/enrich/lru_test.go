@@ -0,0 +1,28 @@
+package enrich
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	c := newLRU(2, time.Hour)
+	now := time.Now()
+
+	c.Add("a", Result{Value: "1"}, now)
+	c.Add("b", Result{Value: "2"}, now)
+	c.Get("a")
+	c.Add("c", Result{Value: "3"}, now)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("least recently used entry is not evicted")
+	}
+	if got, ok := c.Get("a"); !ok || got.Value != "1" {
+		t.Errorf("Get(a) = %v, %v", got, ok)
+	}
+
+	c.Add("old", Result{Value: "4"}, now.Add(-2*time.Hour))
+	if _, ok := c.Get("old"); ok {
+		t.Errorf("expired entry is returned")
+	}
+}
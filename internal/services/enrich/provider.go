@@ -0,0 +1,127 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"test-task/internal/domain/models"
+	"time"
+)
+
+var ErrUnknownProvider = errors.New("unknown enrichment provider")
+
+// Attribute - field of person filled by provider
+type Attribute string
+
+const (
+	AttributeAge         Attribute = "age"
+	AttributeGender      Attribute = "gender"
+	AttributeNationality Attribute = "nationality"
+)
+
+// Result - value fetched by provider. Value is textual: "55", "male", "UA",
+// empty if provider knows nothing about name. Probability is 0 if provider doesn't return it,
+// Count is number of samples of name. FetchedAt is set by Enricher if provider leaves it zero.
+// Candidates are all values of provider ranked by probability, Value is the first of them
+type Result struct {
+	Value       string
+	Probability float64
+	Count       int
+	Candidates  []models.Candidate
+	FetchedAt   time.Time
+}
+
+// Provider - source of one person attribute by name.
+// country is ISO 3166-1 alpha-2 code to localize result, empty - worldwide.
+// Providers which can't localize ignore it
+type Provider interface {
+	Attribute() Attribute
+	Name() string
+	Fetch(ctx context.Context, name string, country string) (*Result, error)
+}
+
+// Registry - providers used by Enricher, one per attribute
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[Attribute]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[Attribute]Provider, len(providers))}
+
+	for _, p := range providers {
+		r.Register(p)
+	}
+
+	return r
+}
+
+// NewRegistryByNames registers providers from available by names in given order.
+// Two providers of one attribute are not allowed
+func NewRegistryByNames(names []string, available ...Provider) (*Registry, error) {
+	byName := make(map[string]Provider, len(available))
+	for _, p := range available {
+		byName[p.Name()] = p
+	}
+
+	r := NewRegistry()
+
+	for _, name := range names {
+		p, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w:%s", ErrUnknownProvider, name)
+		}
+
+		if registered, ok := r.Provider(p.Attribute()); ok {
+			return nil, fmt.Errorf("providers %s and %s both enrich %s", registered.Name(), name, p.Attribute())
+		}
+
+		r.Register(p)
+	}
+
+	return r, nil
+}
+
+// Register adds provider or swaps provider of the same attribute
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[p.Attribute()] = p
+}
+
+// Unregister disables enrichment of attribute
+func (r *Registry) Unregister(attribute Attribute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.providers, attribute)
+}
+
+func (r *Registry) Provider(attribute Attribute) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.providers[attribute]
+
+	return p, ok
+}
+
+// Providers returns registered providers ordered by attribute
+func (r *Registry) Providers() []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	providers := make([]Provider, 0, len(r.providers))
+	for _, p := range r.providers {
+		providers = append(providers, p)
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Attribute() < providers[j].Attribute()
+	})
+
+	return providers
+}
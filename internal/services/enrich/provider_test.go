@@ -0,0 +1,90 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type stubProvider struct {
+	name      string
+	attribute Attribute
+}
+
+func (p stubProvider) Name() string         { return p.name }
+func (p stubProvider) Attribute() Attribute { return p.attribute }
+
+func (p stubProvider) Fetch(ctx context.Context, name string, country string) (*Result, error) {
+	return &Result{}, nil
+}
+
+func TestNewRegistryByNames(t *testing.T) {
+	agify := stubProvider{name: "agify", attribute: AttributeAge}
+	otherAge := stubProvider{name: "other-age", attribute: AttributeAge}
+	genderize := stubProvider{name: "genderize", attribute: AttributeGender}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    []Provider
+		wantErr error
+	}{
+		{
+			name:  "ordered by attribute",
+			names: []string{"genderize", "agify"},
+			want:  []Provider{agify, genderize},
+		},
+		{
+			name:  "disabled provider",
+			names: []string{"genderize"},
+			want:  []Provider{genderize},
+		},
+		{
+			name:    "unknown provider",
+			names:   []string{"agify", "unknown"},
+			wantErr: ErrUnknownProvider,
+		},
+		{
+			name:    "two providers of attribute",
+			names:   []string{"agify", "other-age"},
+			wantErr: errors.New("any"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRegistryByNames(tt.names, agify, otherAge, genderize)
+			if (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("NewRegistryByNames() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if errors.Is(tt.wantErr, ErrUnknownProvider) && !errors.Is(err, ErrUnknownProvider) {
+				t.Errorf("NewRegistryByNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if providers := got.Providers(); !reflect.DeepEqual(providers, tt.want) {
+				t.Errorf("Registry.Providers() = %v, want %v", providers, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterSwap(t *testing.T) {
+	agify := stubProvider{name: "agify", attribute: AttributeAge}
+	otherAge := stubProvider{name: "other-age", attribute: AttributeAge}
+
+	r := NewRegistry(agify)
+	r.Register(otherAge)
+
+	if p, _ := r.Provider(AttributeAge); p != otherAge {
+		t.Errorf("Registry.Provider() = %v, want %v", p, otherAge)
+	}
+
+	r.Unregister(AttributeAge)
+
+	if _, ok := r.Provider(AttributeAge); ok {
+		t.Errorf("Registry.Provider() found unregistered attribute")
+	}
+}
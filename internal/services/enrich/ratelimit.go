@@ -0,0 +1,201 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"test-task/internal/lib/audit"
+	"time"
+)
+
+var ErrQuotaExhausted = errors.New("provider quota exhausted")
+
+const (
+	RateLimitRemainingHeader = "X-Rate-Limit-Remaining"
+	RateLimitResetHeader     = "X-Rate-Limit-Reset"
+)
+
+// RateLimitPolicy - what to do when provider quota is exhausted
+type RateLimitPolicy string
+
+const (
+	// RateLimitQueue - wait for quota not longer than MaxWait
+	RateLimitQueue RateLimitPolicy = "queue"
+	// RateLimitDegrade - don't wait, leave attribute empty
+	RateLimitDegrade RateLimitPolicy = "degrade"
+)
+
+const (
+	DefaultRateLimitPeriod  = 24 * time.Hour
+	DefaultRateLimitMaxWait = 10 * time.Second
+)
+
+// RateLimitConfig - local quota of every provider: Limit names per Period, batch takes one per name.
+// Zero Limit disables local quota, provider headers are respected anyway
+type RateLimitConfig struct {
+	Limit   int
+	Period  time.Duration
+	Policy  RateLimitPolicy
+	MaxWait time.Duration
+}
+
+func (c RateLimitConfig) withDefaults() RateLimitConfig {
+	if c.Period <= 0 {
+		c.Period = DefaultRateLimitPeriod
+	}
+	if c.MaxWait <= 0 {
+		c.MaxWait = DefaultRateLimitMaxWait
+	}
+	if c.Policy == "" {
+		c.Policy = RateLimitQueue
+	}
+
+	return c
+}
+
+func (c RateLimitConfig) validate() error {
+	switch c.Policy {
+	case "", RateLimitQueue, RateLimitDegrade:
+		return nil
+	}
+
+	return fmt.Errorf("unknown rate limit policy %q", c.Policy)
+}
+
+// tokenBucket - local quota of provider synced with its rate limit headers.
+// Tokens can go negative: it's reserved future quota of waiting calls
+type tokenBucket struct {
+	mu           sync.Mutex
+	capacity     float64
+	rate         float64 // tokens per second, 0 - unlimited
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	now          func() time.Time
+}
+
+func newTokenBucket(limit int, period time.Duration) *tokenBucket {
+	b := &tokenBucket{
+		capacity: float64(limit),
+		tokens:   float64(limit),
+		now:      time.Now,
+	}
+
+	if limit > 0 {
+		b.rate = float64(limit) / period.Seconds()
+	}
+
+	b.last = b.now()
+
+	return b
+}
+
+// reserve takes n tokens and returns how long to wait for them.
+// Tokens are not taken if wait is longer than maxWait
+func (b *tokenBucket) reserve(n int, maxWait time.Duration) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	var wait time.Duration
+	if b.blockedUntil.After(now) {
+		wait = b.blockedUntil.Sub(now)
+	}
+
+	if b.rate == 0 {
+		return wait, wait <= maxWait
+	}
+
+	tokens := b.advance(now) - float64(n)
+	if tokens < 0 {
+		wait = max(wait, time.Duration(-tokens/b.rate*float64(time.Second)))
+	}
+
+	if wait > maxWait {
+		return wait, false
+	}
+
+	b.tokens = tokens
+	b.last = now
+
+	return wait, true
+}
+
+// advance returns tokens refilled up to now
+func (b *tokenBucket) advance(now time.Time) float64 {
+	return math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+}
+
+// observe syncs bucket with quota reported by provider
+func (b *tokenBucket) observe(remaining int, reset time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	if b.rate > 0 {
+		b.tokens = math.Min(b.advance(now), float64(remaining))
+		b.last = now
+	}
+
+	if remaining > 0 {
+		return
+	}
+
+	if until := now.Add(reset); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+// observeHeaders reads X-Rate-Limit-Remaining and X-Rate-Limit-Reset (seconds) of response
+func (b *tokenBucket) observeHeaders(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get(RateLimitRemainingHeader))
+	if err != nil {
+		return
+	}
+
+	reset, err := strconv.Atoi(header.Get(RateLimitResetHeader))
+	if err != nil || reset < 0 {
+		reset = 0
+	}
+
+	b.observe(remaining, time.Duration(reset)*time.Second)
+}
+
+// wait blocks until quota allows request of names or returns ErrQuotaExhausted.
+// Providers charge quota per name, so batch takes token of every name
+func (a *apiClient) wait(ctx context.Context, names int) error {
+	maxWait := a.rateLimit.MaxWait
+	if a.rateLimit.Policy == RateLimitDegrade {
+		maxWait = 0
+	}
+
+	delay, ok := a.limiter.reserve(names, maxWait)
+	if !ok {
+		return fmt.Errorf("%w: %s: quota is available in %s", ErrQuotaExhausted, a.name, delay.Round(time.Second))
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	a.log.Info("enrichment quota exhausted, waiting",
+		"requestID", audit.FromContext(ctx).RequestID,
+		"provider", a.name,
+		"delay", delay,
+	)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+package enrich
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+
+	b := newTokenBucket(2, 2*time.Second)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	for i := 0; i < 2; i++ {
+		if wait, ok := b.reserve(1, 0); !ok || wait != 0 {
+			t.Fatalf("reserve() %d = %v, %v, want 0, true", i, wait, ok)
+		}
+	}
+
+	if wait, ok := b.reserve(1, 0); ok || wait != time.Second {
+		t.Errorf("reserve() of empty bucket = %v, %v, want 1s, false", wait, ok)
+	}
+	if wait, ok := b.reserve(1, time.Minute); !ok || wait != time.Second {
+		t.Errorf("queued reserve() = %v, %v, want 1s, true", wait, ok)
+	}
+	// следующий ждет и уже зарезервированный токен
+	if wait, ok := b.reserve(1, time.Minute); !ok || wait != 2*time.Second {
+		t.Errorf("second queued reserve() = %v, %v, want 2s, true", wait, ok)
+	}
+}
+
+func TestTokenBucket_reserveBatch(t *testing.T) {
+	now := time.Now()
+
+	b := newTokenBucket(10, 10*time.Second)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	if wait, ok := b.reserve(4, 0); !ok || wait != 0 {
+		t.Fatalf("reserve() of 4 names = %v, %v, want 0, true", wait, ok)
+	}
+	// квота считается по именам, 6 оставшихся не хватает на 10
+	if wait, ok := b.reserve(10, 0); ok || wait != 4*time.Second {
+		t.Errorf("reserve() of 10 names = %v, %v, want 4s, false", wait, ok)
+	}
+	if wait, ok := b.reserve(6, 0); !ok || wait != 0 {
+		t.Errorf("reserve() of 6 names = %v, %v, want 0, true", wait, ok)
+	}
+}
+
+func TestTokenBucket_observeHeaders(t *testing.T) {
+	now := time.Now()
+
+	b := newTokenBucket(0, time.Hour)
+	b.now = func() time.Time { return now }
+
+	header := http.Header{}
+	header.Set(RateLimitRemainingHeader, "5")
+	header.Set(RateLimitResetHeader, "30")
+
+	b.observeHeaders(header)
+
+	if wait, ok := b.reserve(1, 0); !ok || wait != 0 {
+		t.Errorf("reserve() with remaining quota = %v, %v, want 0, true", wait, ok)
+	}
+
+	header.Set(RateLimitRemainingHeader, "0")
+
+	b.observeHeaders(header)
+
+	if wait, ok := b.reserve(1, 0); ok || wait != 30*time.Second {
+		t.Errorf("reserve() with exhausted quota = %v, %v, want 30s, false", wait, ok)
+	}
+
+	now = now.Add(30 * time.Second)
+
+	if wait, ok := b.reserve(1, 0); !ok || wait != 0 {
+		t.Errorf("reserve() after reset = %v, %v, want 0, true", wait, ok)
+	}
+}
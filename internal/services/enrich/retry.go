@@ -0,0 +1,164 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"test-task/internal/lib/audit"
+	"time"
+)
+
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+	DefaultRetryMaxDelay  = 5 * time.Second
+)
+
+// RetryConfig - retry policy of provider. MaxAttempts 1 disables retries
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = DefaultRetryAttempts
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = DefaultRetryBaseDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultRetryMaxDelay
+	}
+
+	return c
+}
+
+// backoff returns delay before retry after attempt (from 1): exponential with full jitter
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	// clamped before shift: BaseDelay<<shift overflows for large attempts
+	delay := c.MaxDelay
+	if shift := max(attempt-1, 0); shift < 63 && c.BaseDelay <= c.MaxDelay>>shift {
+		delay = c.BaseDelay << shift
+	}
+
+	return rand.N(delay) + 1
+}
+
+// StatusError - provider answered with unexpected status code
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// retryable reports whether err is transient and how long provider asked to wait
+func retryable(err error) (bool, time.Duration) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return true, statusErr.RetryAfter
+		case http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true, 0
+		}
+
+		return false, 0
+	}
+
+	// ошибки сети и таймаут попытки
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+// requestError - request was not answered
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string { return fmt.Sprintf("request failed: %s", e.err) }
+func (e *requestError) Unwrap() error { return e.err }
+
+// parseRetryAfter parses Retry-After header in seconds or http date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
+
+// fetch calls fetchAPI until success, permanent error or the last attempt.
+// Every attempt takes quota of names
+func (a *apiClient) fetch(ctx context.Context, url string, names int, target interface{}) error {
+	requestID := audit.FromContext(ctx).RequestID
+
+	for attempt := 1; ; attempt++ {
+		if err := a.wait(ctx, names); err != nil {
+			return err
+		}
+
+		err := a.fetchAPI(ctx, url, target)
+		if err == nil {
+			return nil
+		}
+
+		ok, retryAfter := retryable(err)
+		if !ok || attempt >= a.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		delay := a.retry.backoff(attempt)
+		if retryAfter > 0 {
+			if retryAfter > a.retry.MaxDelay {
+				a.log.Warn("provider asked to retry too late",
+					"requestID", requestID, "provider", a.name, "retryAfter", retryAfter)
+
+				return err
+			}
+			delay = retryAfter
+		}
+
+		// не начинаем попытку, которая точно не успеет до дедлайна запроса
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return err
+		}
+
+		a.log.Warn("enrichment fetch failed, retrying",
+			"requestID", requestID,
+			"provider", a.name,
+			"attempt", attempt,
+			"delay", delay,
+			"err", err.Error(),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("%w:%w", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package enrich
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_retryable(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		want           bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name: "server error",
+			err:  &StatusError{StatusCode: http.StatusBadGateway},
+			want: true,
+		},
+		{
+			name:           "rate limited",
+			err:            &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second},
+			want:           true,
+			wantRetryAfter: time.Second,
+		},
+		{
+			name: "client error",
+			err:  &StatusError{StatusCode: http.StatusUnprocessableEntity},
+			want: false,
+		},
+		{
+			name: "network error",
+			err:  &requestError{err: errors.New("connection reset by peer")},
+			want: true,
+		},
+		{
+			name: "decode error",
+			err:  errors.New("json unmarshal failed"),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, retryAfter := retryable(tt.err)
+			if got != tt.want || retryAfter != tt.wantRetryAfter {
+				t.Errorf("retryable() = %v, %v, want %v, %v", got, retryAfter, tt.want, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "seconds", value: "3", want: 3 * time.Second},
+		{name: "invalid", value: "soon", want: 0},
+		{name: "date in past", value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryConfig_backoff(t *testing.T) {
+	c := RetryConfig{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 64; attempt++ {
+		limit := min(c.BaseDelay<<min(attempt-1, 10), c.MaxDelay)
+
+		if got := c.backoff(attempt); got <= 0 || got > limit {
+			t.Errorf("backoff(%d) = %v, want (0, %v]", attempt, got, limit)
+		}
+	}
+}
+
+func TestRetryConfig_backoff_largeAttempt(t *testing.T) {
+	// worker defaults: 10s<<30 overflows time.Duration
+	c := RetryConfig{MaxAttempts: 100, BaseDelay: 10 * time.Second, MaxDelay: time.Hour}
+
+	for _, attempt := range []int{31, 32, 34, 63, 64, 100, math.MaxInt} {
+		if got := c.backoff(attempt); got <= 0 || got > c.MaxDelay {
+			t.Errorf("backoff(%d) = %v, want (0, %v]", attempt, got, c.MaxDelay)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"test-task/internal/domain/models"
+	"test-task/internal/lib/audit"
+	"test-task/internal/storage"
+	"time"
+)
+
+// WorkerActor - actor of changes made by workers in person history
+const WorkerActor = "enrichment-worker"
+
+const (
+	DefaultWorkers      = 4
+	DefaultPollInterval = time.Second
+	DefaultLease        = 2 * time.Minute
+	DefaultMaxAttempts  = 5
+	DefaultRetryDelay   = 10 * time.Second
+	DefaultMaxDelay     = 10 * time.Minute
+)
+
+// QueueStore - persisted queue of people waiting for enrichment
+type QueueStore interface {
+	ClaimEnrichment(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.EnrichmentTask, error)
+	CompleteEnrichment(ctx context.Context, entity *models.Person, retryAt time.Time) error
+	RetryEnrichment(ctx context.Context, id int64, retryAt time.Time) error
+	FailEnrichment(ctx context.Context, id int64) error
+}
+
+// WorkerConfig - Lease is time to enrich claimed person before other worker takes it.
+// Failed enrichment is retried after random delay up to RetryDelay growing twice every attempt
+type WorkerConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration
+	MaxAttempts  int
+	RetryDelay   time.Duration
+}
+
+func (c WorkerConfig) withDefaults() WorkerConfig {
+	if c.Workers < 1 {
+		c.Workers = DefaultWorkers
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.Lease <= 0 {
+		c.Lease = DefaultLease
+	}
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = DefaultRetryDelay
+	}
+
+	return c
+}
+
+// Workers enrich people saved with pending enrichment status
+type Workers struct {
+	log      *slog.Logger
+	enricher *Enricher
+	store    QueueStore
+	cfg      WorkerConfig
+}
+
+func NewWorkers(log *slog.Logger, enricher *Enricher, store QueueStore, cfg WorkerConfig) *Workers {
+	return &Workers{
+		log:      log,
+		enricher: enricher,
+		store:    store,
+		cfg:      cfg.withDefaults(),
+	}
+}
+
+// Run starts workers and blocks till ctx is done and workers finished current people
+func (w *Workers) Run(ctx context.Context) {
+	w.log.Info("enrichment workers started", "workers", w.cfg.Workers)
+
+	var wg sync.WaitGroup
+
+	wg.Add(w.cfg.Workers)
+	for i := 0; i < w.cfg.Workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			w.loop(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	w.log.Info("enrichment workers stopped")
+}
+
+func (w *Workers) loop(ctx context.Context) {
+	ctx = audit.WithMeta(ctx, audit.Meta{Actor: WorkerActor})
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		tasks, err := w.store.ClaimEnrichment(ctx, 1, time.Now().Add(w.cfg.Lease))
+		if err != nil {
+			w.log.Error("can't claim people for enrichment", "err", err.Error())
+		}
+
+		if len(tasks) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+
+			continue
+		}
+
+		for _, task := range tasks {
+			w.process(ctx, task)
+		}
+	}
+}
+
+func (w *Workers) process(ctx context.Context, task *models.EnrichmentTask) {
+	log := w.log.With("id", task.Person.ID, "attempt", task.Attempt)
+
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Lease)
+	defer cancel()
+
+	person, err := w.enricher.Enrich(ctx, task.Person)
+	if err != nil {
+		w.release(ctx, log, task, err)
+
+		return
+	}
+
+	// атрибуты, которые не удалось получить, дообогащаем позже
+	person.EnrichmentStatus = models.EnrichmentDone
+	retryAt := time.Now()
+
+	if person.EnrichmentFailed() {
+		if task.Attempt < w.cfg.MaxAttempts {
+			person.EnrichmentStatus = models.EnrichmentPending
+			retryAt = retryAt.Add(w.retryDelay(task.Attempt))
+		} else {
+			person.EnrichmentStatus = models.EnrichmentFailed
+		}
+	}
+
+	err = w.store.CompleteEnrichment(ctx, person, retryAt)
+	switch {
+	case errors.Is(err, storage.ErrConflict):
+		// имя могли поменять, обогащаем заново
+		log.Info("person changed while enriching, retrying")
+
+		if err := w.store.RetryEnrichment(ctx, task.Person.ID, time.Now()); err != nil {
+			log.Error("can't return person to enrichment queue", "err", err.Error())
+		}
+	case errors.Is(err, storage.ErrIDNotFound):
+		log.Info("person deleted while enriching")
+	case err != nil:
+		log.Error("can't save enriched person", "err", err.Error())
+	default:
+		log.Info("person enriched", "status", person.EnrichmentStatus)
+	}
+}
+
+func (w *Workers) release(ctx context.Context, log *slog.Logger, task *models.EnrichmentTask, cause error) {
+	if task.Attempt >= w.cfg.MaxAttempts {
+		log.Error("enrichment failed", "err", cause.Error())
+
+		if err := w.store.FailEnrichment(context.WithoutCancel(ctx), task.Person.ID); err != nil {
+			log.Error("can't mark enrichment failed", "err", err.Error())
+		}
+
+		return
+	}
+
+	delay := w.retryDelay(task.Attempt)
+
+	log.Warn("enrichment failed, retrying", "delay", delay, "err", cause.Error())
+
+	if err := w.store.RetryEnrichment(context.WithoutCancel(ctx), task.Person.ID, time.Now().Add(delay)); err != nil {
+		log.Error("can't return person to enrichment queue", "err", err.Error())
+	}
+}
+
+func (w *Workers) retryDelay(attempt int) time.Duration {
+	return RetryConfig{BaseDelay: w.cfg.RetryDelay, MaxDelay: DefaultMaxDelay}.backoff(attempt)
+}
@@ -0,0 +1,88 @@
+package enrich
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"test-task/internal/domain/models"
+	"test-task/internal/services/enrich/enrichstub"
+	"testing"
+	"time"
+)
+
+type memQueue struct {
+	completed []*models.Person
+	retried   []int64
+	failed    []int64
+}
+
+func (q *memQueue) ClaimEnrichment(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.EnrichmentTask, error) {
+	return nil, nil
+}
+
+func (q *memQueue) CompleteEnrichment(ctx context.Context, entity *models.Person, retryAt time.Time) error {
+	q.completed = append(q.completed, entity)
+	return nil
+}
+
+func (q *memQueue) RetryEnrichment(ctx context.Context, id int64, retryAt time.Time) error {
+	q.retried = append(q.retried, id)
+	return nil
+}
+
+func (q *memQueue) FailEnrichment(ctx context.Context, id int64) error {
+	q.failed = append(q.failed, id)
+	return nil
+}
+
+func TestWorkers_process(t *testing.T) {
+	tests := []struct {
+		name          string
+		attempt       int
+		failures      int
+		partial       bool
+		wantCompleted int
+		wantRetried   int
+		wantFailed    int
+		wantStatus    string
+	}{
+		{name: "enriched", attempt: 1, wantCompleted: 1, wantStatus: models.EnrichmentDone},
+		{name: "retried", attempt: 1, failures: 3, wantRetried: 1},
+		{name: "last attempt", attempt: 2, failures: 3, wantFailed: 1},
+		{name: "partial", attempt: 1, failures: 3, partial: true, wantCompleted: 1, wantStatus: models.EnrichmentPending},
+		{name: "partial last attempt", attempt: 2, failures: 3, partial: true, wantCompleted: 1, wantStatus: models.EnrichmentFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := enrichstub.Start(enrichstub.Options{})
+			defer srv.Close()
+
+			srv.FailNext(enrichstub.AgifyPath, http.StatusInternalServerError, tt.failures)
+
+			queue := &memQueue{}
+
+			enricher := newTestEnricher(t, srv, func(cfg *Config) { cfg.Partial = tt.partial })
+
+			w := NewWorkers(slog.New(slog.NewTextHandler(io.Discard, nil)), enricher, queue, WorkerConfig{MaxAttempts: 2})
+
+			w.process(context.Background(), &models.EnrichmentTask{
+				Person:  &models.Person{ID: 1, Name: "Oleg", EnrichmentStatus: models.EnrichmentProcessing},
+				Attempt: tt.attempt,
+			})
+
+			if len(queue.completed) != tt.wantCompleted || len(queue.retried) != tt.wantRetried || len(queue.failed) != tt.wantFailed {
+				t.Errorf("completed %d, retried %d, failed %d, want %d, %d, %d",
+					len(queue.completed), len(queue.retried), len(queue.failed),
+					tt.wantCompleted, tt.wantRetried, tt.wantFailed)
+			}
+
+			if tt.wantCompleted == 0 {
+				return
+			}
+			if got := queue.completed[0]; got.EnrichmentStatus != tt.wantStatus || got.Gender != "male" {
+				t.Errorf("completed status %q gender %q, want %q male", got.EnrichmentStatus, got.Gender, tt.wantStatus)
+			}
+		})
+	}
+}
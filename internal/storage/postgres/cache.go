@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test-task/internal/domain/models"
+	"test-task/internal/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	EnrichmentCacheTable = "enrichment_cache"
+
+	AttributeColumn   = "attribute"
+	ValueColumn       = "value"
+	ProbabilityColumn = "probability"
+	CountColumn       = "count"
+	CandidatesColumn  = "candidates"
+	FetchedColumn     = "fetched_at"
+)
+
+// GetEnrichment returns cached enrichment in country fetched after fetchedAfter or storage.ErrCacheMiss
+func (s *PostgreStorage) GetEnrichment(ctx context.Context, name string, attribute string, country string, fetchedAfter time.Time) (*models.Enrichment, error) {
+	query := fmt.Sprintf(`
+	SELECT %s, %s, %s, %s, %s, %s, %s, %s FROM %s
+	WHERE %s = ($1) AND %s = ($2) AND %s = ($3) AND %s > ($4)
+	`, NameColumn, AttributeColumn, CountryIdColumn, ValueColumn, ProbabilityColumn, CountColumn, CandidatesColumn, FetchedColumn,
+		EnrichmentCacheTable,
+		NameColumn, AttributeColumn, CountryIdColumn, FetchedColumn,
+	)
+
+	var e models.Enrichment
+
+	err := s.conn.QueryRow(ctx, query, name, attribute, country, fetchedAfter).Scan(
+		&e.Name,
+		&e.Attribute,
+		&e.Country,
+		&e.Value,
+		&e.Probability,
+		&e.Count,
+		&e.Candidates,
+		&e.FetchedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrCacheMiss
+		}
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+
+		return nil, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	return &e, nil
+}
+
+// SaveEnrichment inserts or refreshes cached enrichment
+func (s *PostgreStorage) SaveEnrichment(ctx context.Context, enrichment *models.Enrichment) error {
+	query := fmt.Sprintf(`
+	INSERT INTO %s
+	(%s, %s, %s, %s, %s, %s, %s, %s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (%s, %s, %s) DO UPDATE
+	SET %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s
+	`, EnrichmentCacheTable,
+		NameColumn, AttributeColumn, CountryIdColumn, ValueColumn, ProbabilityColumn, CountColumn, CandidatesColumn, FetchedColumn,
+		NameColumn, AttributeColumn, CountryIdColumn,
+		ValueColumn, ValueColumn, ProbabilityColumn, ProbabilityColumn, CountColumn, CountColumn,
+		CandidatesColumn, CandidatesColumn, FetchedColumn, FetchedColumn,
+	)
+
+	// candidates column is not null
+	candidates := enrichment.Candidates
+	if candidates == nil {
+		candidates = []models.Candidate{}
+	}
+
+	_, err := s.conn.Exec(ctx, query,
+		enrichment.Name,
+		enrichment.Attribute,
+		enrichment.Country,
+		enrichment.Value,
+		enrichment.Probability,
+		enrichment.Count,
+		candidates,
+		enrichment.FetchedAt,
+	)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"test-task/internal/domain/models"
+	"test-task/internal/lib/audit"
+	"test-task/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	HistoryTable = "people_history"
+
+	PersonIdColumn  = "person_id"
+	OperationColumn = "operation"
+	OldValuesColumn = "old_values"
+	NewValuesColumn = "new_values"
+	RequestIdColumn = "request_id"
+	ActorColumn     = "actor"
+	ChangedColumn   = "changed_at"
+)
+
+// recordHistory writes change of person inside tx of the change itself.
+// Request ID and actor are taken from ctx
+func (s *PostgreStorage) recordHistory(ctx context.Context, tx pgx.Tx, personID int64, operation string, old, new *models.Person) error {
+	meta := audit.FromContext(ctx)
+
+	query := fmt.Sprintf(`
+	INSERT INTO %s
+	(%s, %s, %s, %s, %s, %s) VALUES ($1, $2, $3, $4, $5, $6)
+	`, HistoryTable,
+		PersonIdColumn, OperationColumn, OldValuesColumn, NewValuesColumn, RequestIdColumn, ActorColumn,
+	)
+
+	_, err := tx.Exec(ctx, query,
+		personID,
+		operation,
+		historyValues(old),
+		historyValues(new),
+		meta.RequestID,
+		meta.Actor,
+	)
+	if err != nil {
+		s.log.Error("can't record history", "err", err.Error())
+		s.log.Debug("can't record history", "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%w:can't record history:%w", ErrQuery, err)
+	}
+
+	return nil
+}
+
+// recordCreatedBatch writes create history of saved batch with COPY
+func (s *PostgreStorage) recordCreatedBatch(ctx context.Context, tx pgx.Tx, entities []*models.Person) error {
+	meta := audit.FromContext(ctx)
+
+	_, err := tx.CopyFrom(ctx,
+		pgx.Identifier{HistoryTable},
+		[]string{PersonIdColumn, OperationColumn, NewValuesColumn, RequestIdColumn, ActorColumn},
+		pgx.CopyFromSlice(len(entities), func(i int) ([]any, error) {
+			entity := entities[i]
+
+			return []any{entity.ID, models.OperationCreate, historyValues(entity), meta.RequestID, meta.Actor}, nil
+		}),
+	)
+	if err != nil {
+		s.log.Error("can't record history", "err", err.Error())
+
+		return fmt.Errorf("%w:can't record history:%w", ErrQuery, err)
+	}
+
+	return nil
+}
+
+// History returns all changes of person from the oldest.
+// Works for deleted and purged people too
+func (s *PostgreStorage) History(ctx context.Context, personID int64) ([]*models.PersonChange, error) {
+	query := fmt.Sprintf(`
+	SELECT %s, %s, %s, %s, %s, %s, %s, %s FROM %s
+	WHERE %s = ($1)
+	ORDER BY %s, %s
+	`, IdColumn, PersonIdColumn, OperationColumn, OldValuesColumn, NewValuesColumn, RequestIdColumn, ActorColumn, ChangedColumn,
+		HistoryTable,
+		PersonIdColumn,
+		ChangedColumn, IdColumn,
+	)
+
+	rows, err := s.conn.Query(ctx, query, personID)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+
+		return nil, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+	defer rows.Close()
+
+	history := []*models.PersonChange{}
+
+	for rows.Next() {
+		var change models.PersonChange
+
+		err := rows.Scan(
+			&change.ID,
+			&change.PersonID,
+			&change.Operation,
+			&change.OldValues,
+			&change.NewValues,
+			&change.RequestID,
+			&change.Actor,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			s.log.Error("can't scan row", "err", err.Error())
+			return nil, fmt.Errorf("can't scan row: %w", err)
+		}
+		history = append(history, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.log.Error("rows error", "err", err.Error())
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if len(history) == 0 {
+		return nil, storage.ErrIDNotFound
+	}
+
+	return history, nil
+}
+
+// historyValues - snapshot of person fields for history. nil person is stored as NULL
+func historyValues(p *models.Person) any {
+	if p == nil {
+		return nil
+	}
+
+	return map[string]any{
+		NameColumn:        p.Name,
+		SurnameColumn:     p.Surname,
+		PatronymicColumn:  p.Patronymic,
+		AgeColumn:         p.Age,
+		GenderColumn:      p.Gender,
+		NationalityColumn: p.Nationality,
+		VersionColumn:     p.Version,
+	}
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"test-task/internal/domain/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	NationalitiesTable = "people_nationalities"
+
+	CountryIdColumn = "country_id"
+)
+
+// nationalitiesColumn - candidates of person as JSON array, selected with personColumns
+var nationalitiesColumn = fmt.Sprintf(`(
+	SELECT COALESCE(jsonb_agg(jsonb_build_object('%s', %s, '%s', %s) ORDER BY %s DESC, %s), '[]')
+	FROM %s WHERE %s = %s.%s
+	)`,
+	CountryIdColumn, CountryIdColumn, ProbabilityColumn, ProbabilityColumn, ProbabilityColumn, CountryIdColumn,
+	NationalitiesTable, PersonIdColumn, PeopleTable, IdColumn,
+)
+
+// saveNationalities replaces candidates of entities inside tx of their change
+func (s *PostgreStorage) saveNationalities(ctx context.Context, tx pgx.Tx, entities ...*models.Person) error {
+	ids := make([]int64, 0, len(entities))
+	rows := make([][]any, 0, len(entities))
+
+	for _, entity := range entities {
+		ids = append(ids, entity.ID)
+
+		for _, candidate := range entity.Nationalities {
+			rows = append(rows, []any{entity.ID, candidate.CountryID, candidate.Probability})
+		}
+	}
+
+	query := fmt.Sprintf(`
+	DELETE FROM %s WHERE %s = ANY($1)
+	`, NationalitiesTable, PersonIdColumn)
+
+	_, err := tx.Exec(ctx, query, ids)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{NationalitiesTable},
+		[]string{PersonIdColumn, CountryIdColumn, ProbabilityColumn},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		s.log.Error("can't copy nationalities", "err", err.Error())
+
+		return fmt.Errorf("%w:can't copy nationalities:%w", ErrQuery, err)
+	}
+
+	return nil
+}
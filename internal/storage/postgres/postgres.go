@@ -8,8 +8,10 @@ import (
 	"strings"
 	"test-task/internal/domain/filters"
 	"test-task/internal/domain/models"
+	"test-task/internal/lib/audit"
 	"test-task/internal/storage"
 	"time"
+	"unicode"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -27,6 +29,19 @@ const (
 	NationalityColumn = "nationality"
 	CreatedColumn     = "created_at"
 	UpdatedColum      = "updated_at"
+	DeletedColumn     = "deleted_at"
+	VersionColumn     = "version"
+	SearchColumn      = "search_vector"
+
+	EnrichmentStatusColumn   = "enrichment_status"
+	EnrichmentAttemptsColumn = "enrichment_attempts"
+	EnrichmentNextColumn     = "enrichment_next_at"
+	EnrichmentColumn         = "enrichment"
+	ReviewColumn             = "needs_review"
+	CountryHintColumn        = "country_hint"
+
+	// searchConfig - text search configuration without stemming, names are not words
+	searchConfig = "simple"
 )
 
 var (
@@ -36,18 +51,56 @@ var (
 	ErrQuery         = errors.New("can't do query")
 )
 
+// sortableColumns - whitelist of columns allowed in ORDER BY
+var sortableColumns = map[string]string{
+	IdColumn:          IdColumn,
+	NameColumn:        NameColumn,
+	SurnameColumn:     SurnameColumn,
+	PatronymicColumn:  PatronymicColumn,
+	AgeColumn:         AgeColumn,
+	GenderColumn:      GenderColumn,
+	NationalityColumn: NationalityColumn,
+	CreatedColumn:     CreatedColumn,
+	UpdatedColum:      UpdatedColum,
+}
+
+// personColumns - columns of StoragePerson in scanPerson order
+var personColumns = strings.Join([]string{
+	IdColumn, NameColumn, SurnameColumn, PatronymicColumn, AgeColumn, GenderColumn, NationalityColumn,
+	nationalitiesColumn, CountryHintColumn, EnrichmentStatusColumn, EnrichmentColumn, CreatedColumn, UpdatedColum, VersionColumn,
+}, ", ")
+
+// nullableColumns - sort expressions of columns which are null if not enriched.
+// Nulls are sorted as zero values, so keyset of such rows is comparable.
+// Expressions are indexed as is by migration 000017, keep them in sync
+var nullableColumns = map[string]string{
+	AgeColumn:         "COALESCE(" + AgeColumn + ", 0)",
+	GenderColumn:      "COALESCE(" + GenderColumn + ", '')",
+	NationalityColumn: "COALESCE(" + NationalityColumn + ", '')",
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 type PostgreStorage struct {
 	conn *pgxpool.Pool
 	log  *slog.Logger
 }
 
 type StoragePerson struct {
-	Name        string
-	Surname     string
-	Patronymic  string
-	Age         int
-	Gender      string
-	Nationality string
+	ID               int64
+	Name             string
+	Surname          string
+	Patronymic       string
+	Age              *int
+	Gender           *string
+	Nationality      *string
+	Nationalities    []models.NationalityCandidate
+	CountryHint      *string
+	EnrichmentStatus string
+	Enrichment       map[string]*models.AttributeEnrichment
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Version          int
 }
 
 func New(ctx context.Context, log *slog.Logger, connString string) (*PostgreStorage, error) {
@@ -102,25 +155,33 @@ func (s *PostgreStorage) Save(ctx context.Context, entity *models.Person) (int64
 		return 0, fmt.Errorf("%w:%w", ErrTxBegin, err)
 	}
 
-	tx.Begin(ctx)
 	defer tx.Rollback(ctx)
 
+	if entity.EnrichmentStatus == "" {
+		entity.EnrichmentStatus = models.EnrichmentDone
+	}
+
 	query := fmt.Sprintf(`
 	INSERT INTO %s
-	(%s, %s, %s, %s, %s, %s) VALUES ($1, $2, $3, $4, $5, $6) 
-	RETURNING %s
+	(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
+	RETURNING %s, %s, %s, %s
 	`, PeopleTable,
-		NameColumn, SurnameColumn, PatronymicColumn, AgeColumn, GenderColumn, NationalityColumn,
-		IdColumn,
+		NameColumn, SurnameColumn, PatronymicColumn, AgeColumn, GenderColumn, NationalityColumn, CountryHintColumn,
+		EnrichmentStatusColumn, EnrichmentColumn, ReviewColumn,
+		IdColumn, CreatedColumn, UpdatedColum, VersionColumn,
 	)
 
-	err = s.conn.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		entity.Name,
 		entity.Surname,
 		entity.Patronymic,
-		entity.Age,
-		entity.Gender,
-		entity.Nationality).Scan(&id)
+		nullable(entity.Age),
+		nullable(entity.Gender),
+		nullable(entity.Nationality),
+		nullable(entity.CountryHint),
+		entity.EnrichmentStatus,
+		enrichmentValue(entity),
+		entity.NeedsReview()).Scan(&id, &entity.CreatedAt, &entity.UpdatedAt, &entity.Version)
 
 	if err != nil {
 		s.log.Error(ErrQuery.Error(), "err", err.Error())
@@ -129,6 +190,18 @@ func (s *PostgreStorage) Save(ctx context.Context, entity *models.Person) (int64
 		return 0, fmt.Errorf("%s:%w", ErrQuery, err)
 	}
 
+	entity.ID = id
+
+	err = s.saveNationalities(ctx, tx, entity)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.recordHistory(ctx, tx, id, models.OperationCreate, nil, entity)
+	if err != nil {
+		return 0, err
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		s.log.Error(ErrTxCommit.Error(), "err", err.Error())
@@ -140,6 +213,97 @@ func (s *PostgreStorage) Save(ctx context.Context, entity *models.Person) (int64
 	return id, nil
 }
 
+// SaveBatch saves all entities in one transaction with COPY.
+// ids are taken from sequence beforehand, because COPY can't return them
+func (s *PostgreStorage) SaveBatch(ctx context.Context, entities []*models.Person) ([]int64, error) {
+
+	if len(entities) == 0 {
+		return []int64{}, nil
+	}
+
+	tx, err := s.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		s.log.Error(ErrTxBegin.Error(), "err", err.Error())
+
+		return nil, fmt.Errorf("%w:%w", ErrTxBegin, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	idsQuery := fmt.Sprintf(`
+	SELECT nextval(pg_get_serial_sequence('%s', '%s')) FROM generate_series(1, $1)
+	`, PeopleTable, IdColumn)
+
+	rows, err := tx.Query(ctx, idsQuery, len(entities))
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", idsQuery)
+
+		return nil, fmt.Errorf("%s:%w", ErrQuery, err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		s.log.Error("can't scan ids", "err", err.Error())
+
+		return nil, fmt.Errorf("can't scan ids: %w", err)
+	}
+
+	copied, err := tx.CopyFrom(ctx,
+		pgx.Identifier{PeopleTable},
+		[]string{
+			IdColumn, NameColumn, SurnameColumn, PatronymicColumn, AgeColumn, GenderColumn, NationalityColumn, CountryHintColumn,
+			EnrichmentStatusColumn, EnrichmentColumn, ReviewColumn,
+		},
+		pgx.CopyFromSlice(len(entities), func(i int) ([]any, error) {
+			entity := entities[i]
+
+			if entity.EnrichmentStatus == "" {
+				entity.EnrichmentStatus = models.EnrichmentDone
+			}
+
+			return []any{
+				ids[i], entity.Name, entity.Surname, entity.Patronymic,
+				nullable(entity.Age), nullable(entity.Gender), nullable(entity.Nationality), nullable(entity.CountryHint),
+				entity.EnrichmentStatus, enrichmentValue(entity), entity.NeedsReview(),
+			}, nil
+		}),
+	)
+	if err != nil {
+		s.log.Error("can't copy people", "err", err.Error())
+
+		return nil, fmt.Errorf("%w:can't copy people:%w", ErrQuery, err)
+	}
+
+	for i, entity := range entities {
+		entity.ID = ids[i]
+		entity.Version = 1
+	}
+
+	err = s.saveNationalities(ctx, tx, entities...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.recordCreatedBatch(ctx, tx, entities)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.log.Error(ErrTxCommit.Error(), "err", err.Error())
+
+		return nil, fmt.Errorf("%s:%w", ErrTxCommit, err)
+	}
+
+	s.log.Debug("people batch saved", "count", copied)
+
+	return ids, nil
+}
+
+// Delete is soft: person is hidden until Restore or Purge.
+// New version makes ETags issued before delete stale
 func (s *PostgreStorage) Delete(ctx context.Context, id int64) error {
 	tx, err := s.conn.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
@@ -148,26 +312,36 @@ func (s *PostgreStorage) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("%w:%w", ErrTxBegin, err)
 	}
 
-	tx.Begin(ctx)
 	defer tx.Rollback(ctx)
 
 	query := fmt.Sprintf(`
-	DELETE FROM %s
-	WHERE %s = ($1)
+	UPDATE %s
+	SET %s = CURRENT_TIMESTAMP, %s = %s + 1
+	WHERE %s = ($1) AND %s IS NULL
+	RETURNING %s
 	`, PeopleTable,
-		IdColumn,
+		DeletedColumn, VersionColumn, VersionColumn,
+		IdColumn, DeletedColumn,
+		personColumns,
 	)
 
-	commandTag, err := s.conn.Exec(ctx, query, id)
+	var deleted StoragePerson
+
+	err = scanPerson(tx.QueryRow(ctx, query, id), &deleted)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.log.Debug("ID was not found")
+			return storage.ErrIDNotFound
+		}
 		s.log.Error(ErrQuery.Error(), "err", err.Error())
 		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
 
 		return fmt.Errorf("%s:%w", ErrQuery, err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return storage.ErrIDNotFound
+	err = s.recordHistory(ctx, tx, id, models.OperationDelete, deleted.model(), nil)
+	if err != nil {
+		return err
 	}
 
 	err = tx.Commit(ctx)
@@ -181,6 +355,88 @@ func (s *PostgreStorage) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// Restore brings back soft deleted person of new version
+func (s *PostgreStorage) Restore(ctx context.Context, id int64) error {
+	tx, err := s.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		s.log.Error(ErrTxBegin.Error(), "err", err.Error())
+
+		return fmt.Errorf("%w:%w", ErrTxBegin, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`
+	UPDATE %s
+	SET %s = NULL, %s = %s + 1
+	WHERE %s = ($1) AND %s IS NOT NULL
+	RETURNING %s
+	`, PeopleTable,
+		DeletedColumn, VersionColumn, VersionColumn,
+		IdColumn, DeletedColumn,
+		personColumns,
+	)
+
+	var restored StoragePerson
+
+	err = scanPerson(tx.QueryRow(ctx, query, id), &restored)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.log.Debug("deleted ID was not found")
+			return storage.ErrIDNotFound
+		}
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%s:%w", ErrQuery, err)
+	}
+
+	err = s.recordHistory(ctx, tx, id, models.OperationRestore, nil, restored.model())
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.log.Error(ErrTxCommit.Error(), "err", err.Error())
+		s.log.Debug(ErrTxCommit.Error(), "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%s:%w", ErrTxCommit, err)
+	}
+
+	return nil
+}
+
+// Purge removes forever people soft deleted before deletedBefore, history of every one gets purge record
+func (s *PostgreStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	meta := audit.FromContext(ctx)
+
+	query := fmt.Sprintf(`
+	WITH purged AS (
+		DELETE FROM %s
+		WHERE %s < ($1)
+		RETURNING %s
+	)
+	INSERT INTO %s (%s, %s, %s, %s)
+	SELECT %s, ($2), ($3), ($4) FROM purged
+	`, PeopleTable,
+		DeletedColumn,
+		IdColumn,
+		HistoryTable, PersonIdColumn, OperationColumn, RequestIdColumn, ActorColumn,
+		IdColumn,
+	)
+
+	commandTag, err := s.conn.Exec(ctx, query, deletedBefore, models.OperationPurge, meta.RequestID, meta.Actor)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return 0, fmt.Errorf("%s:%w", ErrQuery, err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
 func (s *PostgreStorage) FindByID(ctx context.Context, id int64) (*models.Person, error) {
 
 	tx, err := s.conn.BeginTx(ctx, pgx.TxOptions{})
@@ -196,26 +452,14 @@ func (s *PostgreStorage) FindByID(ctx context.Context, id int64) (*models.Person
 	result := StoragePerson{}
 
 	query := fmt.Sprintf(`
-	SELECT %s, %s, %s, %s, %s, %s FROM %s
-	WHERE %s = ($1)
-	`, NameColumn,
-		SurnameColumn,
-		PatronymicColumn,
-		AgeColumn,
-		GenderColumn,
-		NationalityColumn,
+	SELECT %s FROM %s
+	WHERE %s = ($1) AND %s IS NULL
+	`, personColumns,
 		PeopleTable,
-		IdColumn,
+		IdColumn, DeletedColumn,
 	)
 
-	err = s.conn.QueryRow(ctx, query, id).Scan(
-		&result.Name,
-		&result.Surname,
-		&result.Patronymic,
-		&result.Age,
-		&result.Gender,
-		&result.Nationality,
-	)
+	err = scanPerson(s.conn.QueryRow(ctx, query, id), &result)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			s.log.Debug("ID was not found")
@@ -233,18 +477,11 @@ func (s *PostgreStorage) FindByID(ctx context.Context, id int64) (*models.Person
 		return nil, fmt.Errorf("%s:%w", ErrTxCommit, err)
 	}
 
-	personModel := models.Person{
-		Name:        result.Name,
-		Surname:     result.Surname,
-		Patronymic:  result.Patronymic,
-		Age:         result.Age,
-		Gender:      result.Gender,
-		Nationality: result.Nationality,
-	}
-
-	return &personModel, nil
+	return result.model(), nil
 }
 
+// Update writes entity with its nationality candidates if it is still of entity.Version,
+// otherwise returns storage.ErrConflict. On success entity gets new version
 func (s *PostgreStorage) Update(ctx context.Context, entity *models.Person, id int64) error {
 	tx, err := s.conn.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel: pgx.ReadCommitted,
@@ -255,9 +492,37 @@ func (s *PostgreStorage) Update(ctx context.Context, entity *models.Person, id i
 		return fmt.Errorf("%w:%w", ErrTxBegin, err)
 	}
 
-	tx.Begin(ctx)
 	defer tx.Rollback(ctx)
 
+	// old values for history, row is locked till commit
+	oldQuery := fmt.Sprintf(`
+	SELECT %s FROM %s
+	WHERE %s = ($1) AND %s IS NULL
+	FOR UPDATE
+	`, personColumns,
+		PeopleTable,
+		IdColumn, DeletedColumn,
+	)
+
+	var old StoragePerson
+
+	err = scanPerson(tx.QueryRow(ctx, oldQuery, id), &old)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.log.Debug("ID was not found")
+			return storage.ErrIDNotFound
+		}
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", oldQuery)
+
+		return fmt.Errorf("%s:%w", ErrQuery, err)
+	}
+
+	if old.Version != entity.Version {
+		s.log.Debug("version conflict", "id", id, "version", old.Version, "expected", entity.Version)
+		return fmt.Errorf("%w:version is %d, expected %d", storage.ErrConflict, old.Version, entity.Version)
+	}
+
 	query := fmt.Sprintf(`
 	   UPDATE %s
         SET
@@ -266,9 +531,12 @@ func (s *PostgreStorage) Update(ctx context.Context, entity *models.Person, id i
 			%s = ($3),
 			%s = ($4),
 			%s = ($5),
-			%s = ($6)
-        WHERE %s = ($7)
-		RETURNING %s;
+			%s = ($6),
+			%s = ($7),
+			%s = ($8),
+			%s = %s + 1
+        WHERE %s = ($9) AND %s IS NULL
+		RETURNING %s, %s, %s, %s, %s;
 		`,
 		PeopleTable,
 		NameColumn,
@@ -277,24 +545,43 @@ func (s *PostgreStorage) Update(ctx context.Context, entity *models.Person, id i
 		AgeColumn,
 		GenderColumn,
 		NationalityColumn,
-		IdColumn,
-		IdColumn,
+		EnrichmentColumn,
+		ReviewColumn,
+		VersionColumn, VersionColumn,
+		IdColumn, DeletedColumn,
+		IdColumn, EnrichmentStatusColumn, CreatedColumn, UpdatedColum, VersionColumn,
 	)
 
-	_, err = s.conn.Exec(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		entity.Name,
 		entity.Surname,
 		entity.Patronymic,
-		entity.Age,
-		entity.Gender,
-		entity.Nationality,
-		id)
+		nullable(entity.Age),
+		nullable(entity.Gender),
+		nullable(entity.Nationality),
+		enrichmentValue(entity),
+		entity.NeedsReview(),
+		id).Scan(&entity.ID, &entity.EnrichmentStatus, &entity.CreatedAt, &entity.UpdatedAt, &entity.Version)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			s.log.Debug("ID was not found")
 			return storage.ErrIDNotFound
 		}
-		s.log.Error(storage.ErrIDNotFound.Error())
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%s:%w", ErrQuery, err)
+	}
+
+	// candidates follow nationality of entity, manual one has none
+	err = s.saveNationalities(ctx, tx, entity)
+	if err != nil {
+		return err
+	}
+
+	err = s.recordHistory(ctx, tx, id, models.OperationUpdate, old.model(), entity)
+	if err != nil {
+		return err
 	}
 
 	err = tx.Commit(ctx)
@@ -322,14 +609,23 @@ func (s *PostgreStorage) FilteredPages(ctx context.Context, offset int, limit in
 	list := []*models.Person{}
 
 	query := fmt.Sprintf(`
-		SELECT %s, %s, %s, %s, %s, %s 
+		SELECT %s 
 		FROM %s `,
-		NameColumn, SurnameColumn, PatronymicColumn, AgeColumn, GenderColumn, NationalityColumn,
+		personColumns,
 		PeopleTable,
 	)
 
 	query, args := filter(query, options)
 
+	spec, err := sortSpec(options.Sort)
+	if err != nil {
+		s.log.Error("invalid sort", "err", err.Error())
+
+		return nil, 0, err
+	}
+
+	query = orderBy(query, spec)
+
 	count, err := s.countPeople(ctx, options, args)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -360,22 +656,85 @@ func (s *PostgreStorage) FilteredPages(ctx context.Context, offset int, limit in
 	defer rows.Close()
 
 	for rows.Next() {
-		var p models.Person
+		var p StoragePerson
+
+		err := scanPerson(rows, &p)
+
+		if err != nil {
+			s.log.Error("can't scan row", "err", err.Error())
+			return nil, 0, fmt.Errorf("can't scan row: %w", err)
+		}
+		list = append(list, p.model())
+	}
+
+	if err := rows.Err(); err != nil {
+		s.log.Error("rows error", "err", err.Error())
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return list, count, nil
+
+}
+
+// Search finds people by words of name, surname and patronymic in any order.
+// Every word is matched as prefix, results are ordered by relevance
+func (s *PostgreStorage) Search(ctx context.Context, text string, offset int, limit int) ([]*models.Person, int, error) {
+
+	list := []*models.Person{}
+
+	tsQuery := searchQuery(text)
+	if tsQuery == "" {
+		return list, 0, nil
+	}
+
+	var count int
+
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s
+		WHERE %s @@ to_tsquery('%s', $1) AND %s IS NULL`,
+		PeopleTable,
+		SearchColumn, searchConfig, DeletedColumn,
+	)
+
+	err := s.conn.QueryRow(ctx, countQuery, tsQuery).Scan(&count)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", countQuery)
+
+		return nil, 0, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
 
-		err := rows.Scan(
-			&p.Name,
-			&p.Surname,
-			&p.Patronymic,
-			&p.Age,
-			&p.Gender,
-			&p.Nationality,
-		)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s, to_tsquery('%s', $1) AS q
+		WHERE %s @@ q AND %s IS NULL
+		ORDER BY ts_rank(%s, q) DESC, %s ASC
+		OFFSET ($2) LIMIT ($3)`,
+		personColumns,
+		PeopleTable, searchConfig,
+		SearchColumn, DeletedColumn,
+		SearchColumn, IdColumn,
+	)
+
+	s.log.Debug("Search query", "query", query, "tsquery", tsQuery)
+
+	rows, err := s.conn.Query(ctx, query, tsQuery, offset, limit)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+
+		return nil, 0, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p StoragePerson
 
+		err := scanPerson(rows, &p)
 		if err != nil {
 			s.log.Error("can't scan row", "err", err.Error())
 			return nil, 0, fmt.Errorf("can't scan row: %w", err)
 		}
-		list = append(list, &p)
+		list = append(list, p.model())
 	}
 
 	if err := rows.Err(); err != nil {
@@ -384,7 +743,27 @@ func (s *PostgreStorage) FilteredPages(ctx context.Context, offset int, limit in
 	}
 
 	return list, count, nil
+}
+
+// searchQuery builds prefix tsquery from free text: "Ivanov Petr" -> "ivanov:* & petr:*".
+// Everything except letters and digits is dropped, so user input can't break tsquery syntax
+func searchQuery(text string) string {
+	var words []string
+
+	for _, word := range strings.Fields(text) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, word)
+
+		if word != "" {
+			words = append(words, word+":*")
+		}
+	}
 
+	return strings.Join(words, " & ")
 }
 
 func (s *PostgreStorage) countPeople(ctx context.Context, options *filters.Options, args []interface{}) (int, error) {
@@ -410,32 +789,60 @@ func (s *PostgreStorage) countPeople(ctx context.Context, options *filters.Optio
 
 }
 
+// filter always adds WHERE: soft deleted people are never listed
 func filter(query string, options *filters.Options) (string, []interface{}) {
-	var whereClauses []string
+	whereClauses := []string{DeletedColumn + " IS NULL"}
 	var args []interface{}
 	argNum := 1
 
-	if options.Name != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", NameColumn, argNum))
-		args = append(args, *options.Name)
-		argNum++
-	}
-	if options.Surname != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", SurnameColumn, argNum))
-		args = append(args, *options.Surname)
-		argNum++
-	}
-	if options.Patronymic != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", PatronymicColumn, argNum))
-		args = append(args, *options.Patronymic)
-		argNum++
+	// prefix и contains всегда без учета регистра, их обслуживают GIN trgm индексы
+	for _, text := range []struct {
+		column   string
+		exact    *string
+		prefix   *string
+		contains *string
+	}{
+		{NameColumn, options.Name, options.NamePrefix, options.NameContains},
+		{SurnameColumn, options.Surname, options.SurnamePrefix, options.SurnameContains},
+		{PatronymicColumn, options.Patronymic, options.PatronymicPrefix, options.PatronymicContains},
+	} {
+		if text.exact != nil {
+			if options.IgnoreCase {
+				whereClauses = append(whereClauses, fmt.Sprintf("%s ILIKE $%d", text.column, argNum))
+				args = append(args, escapeLike(*text.exact))
+			} else {
+				whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", text.column, argNum))
+				args = append(args, *text.exact)
+			}
+			argNum++
+		}
+		if text.prefix != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("%s ILIKE $%d", text.column, argNum))
+			args = append(args, escapeLike(*text.prefix)+"%")
+			argNum++
+		}
+		if text.contains != nil {
+			whereClauses = append(whereClauses, fmt.Sprintf("%s ILIKE $%d", text.column, argNum))
+			args = append(args, "%"+escapeLike(*text.contains)+"%")
+			argNum++
+		}
 	}
 	if options.Gender != nil {
 		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", GenderColumn, argNum))
 		args = append(args, *options.Gender)
 		argNum++
 	}
-	if options.Nationality != nil {
+	if options.Nationality != nil && options.NationalityProbability != nil {
+		// основная национальность или любой кандидат не ниже вероятности
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"(%s = $%d OR EXISTS (SELECT 1 FROM %s WHERE %s = %s.%s AND %s = $%d AND %s >= $%d))",
+			NationalityColumn, argNum,
+			NationalitiesTable, PersonIdColumn, PeopleTable, IdColumn, CountryIdColumn, argNum, ProbabilityColumn, argNum+1,
+		))
+		args = append(args, *options.Nationality, *options.NationalityProbability)
+		argNum += 2
+	}
+	if options.Nationality != nil && options.NationalityProbability == nil {
 		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", NationalityColumn, argNum))
 		args = append(args, *options.Nationality)
 		argNum++
@@ -446,7 +853,7 @@ func filter(query string, options *filters.Options) (string, []interface{}) {
 		argNum++
 	}
 	if options.Age == nil && options.MinAge != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s => $%d", AgeColumn, argNum))
+		whereClauses = append(whereClauses, fmt.Sprintf("%s >= $%d", AgeColumn, argNum))
 		args = append(args, *options.MinAge)
 		argNum++
 	}
@@ -456,10 +863,348 @@ func filter(query string, options *filters.Options) (string, []interface{}) {
 		argNum++
 	}
 
-	// Добавляем WHERE если есть условия
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
-	}
+	query += " WHERE " + strings.Join(whereClauses, " AND ")
 
 	return query, args
 }
+
+// escapeLike escapes LIKE wildcards, so user input is matched literally
+func escapeLike(value string) string {
+	return likeReplacer.Replace(value)
+}
+
+// KeysetPages returns page of people after (or before if keyset.Backward) the keyset row.
+// nil keyset means the first page. Returned keysets point to next and previous pages, nil if there is no such page
+func (s *PostgreStorage) KeysetPages(ctx context.Context, keyset *filters.Keyset, limit int, options *filters.Options) ([]*models.Person, *filters.Keyset, *filters.Keyset, error) {
+
+	spec, err := sortSpec(options.Sort)
+	if err != nil {
+		s.log.Error("invalid sort", "err", err.Error())
+
+		return nil, nil, nil, err
+	}
+
+	backward := keyset != nil && keyset.Backward
+
+	query := fmt.Sprintf(`
+		SELECT %s 
+		FROM %s `,
+		personColumns,
+		PeopleTable,
+	)
+
+	query, args := filter(query, options)
+
+	if keyset != nil {
+		query, args, err = whereKeyset(query, args, spec, keyset)
+		if err != nil {
+			s.log.Error("invalid keyset", "err", err.Error())
+
+			return nil, nil, nil, err
+		}
+	}
+
+	orderSpec := spec
+	if backward {
+		orderSpec = make([]filters.SortField, len(spec))
+		for i, field := range spec {
+			orderSpec[i] = filters.SortField{Field: field.Field, Desc: !field.Desc}
+		}
+	}
+
+	query = orderBy(query, orderSpec)
+
+	query += fmt.Sprintf(" LIMIT ($%d)", len(args)+1)
+
+	args = append(args, limit+1)
+
+	s.log.Debug("Keyset query statment enriched by filters:", "query", query)
+
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+
+		return nil, nil, nil, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+	defer rows.Close()
+
+	page := []StoragePerson{}
+
+	for rows.Next() {
+		var p StoragePerson
+
+		err := scanPerson(rows, &p)
+		if err != nil {
+			s.log.Error("can't scan row", "err", err.Error())
+			return nil, nil, nil, fmt.Errorf("can't scan row: %w", err)
+		}
+		page = append(page, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		s.log.Error("rows error", "err", err.Error())
+		return nil, nil, nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	hasMore := len(page) > limit
+	if hasMore {
+		page = page[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+			page[i], page[j] = page[j], page[i]
+		}
+	}
+
+	list := make([]*models.Person, 0, len(page))
+	for _, p := range page {
+		list = append(list, p.model())
+	}
+
+	if len(page) == 0 {
+		return list, nil, nil, nil
+	}
+
+	var next, prev *filters.Keyset
+
+	first, last := page[0], page[len(page)-1]
+
+	switch {
+	case backward:
+		next = last.keyset(spec, false)
+		if hasMore {
+			prev = first.keyset(spec, true)
+		}
+	default:
+		if hasMore {
+			next = last.keyset(spec, false)
+		}
+		if keyset != nil {
+			prev = first.keyset(spec, true)
+		}
+	}
+
+	return list, next, prev, nil
+}
+
+// sortSpec validates sort spec by whitelist and appends id
+// as the last key, so rows order is deterministic and pages don't overlap
+func sortSpec(sort []filters.SortField) ([]filters.SortField, error) {
+	spec := make([]filters.SortField, 0, len(sort)+1)
+	hasID := false
+
+	for _, field := range sort {
+		column, ok := sortableColumns[field.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w:unknown field %s", filters.ErrInvalidSort, field.Field)
+		}
+
+		spec = append(spec, filters.SortField{Field: column, Desc: field.Desc})
+
+		if column == IdColumn {
+			hasID = true
+		}
+	}
+
+	if !hasID {
+		spec = append(spec, filters.SortField{Field: IdColumn})
+	}
+
+	return spec, nil
+}
+
+func orderBy(query string, spec []filters.SortField) string {
+	clauses := make([]string, 0, len(spec))
+
+	for _, field := range spec {
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+
+		clauses = append(clauses, sortExpr(field.Field)+" "+direction)
+	}
+
+	return query + " ORDER BY " + strings.Join(clauses, ", ")
+}
+
+// whereKeyset adds condition selecting rows after (before) keyset row in spec order:
+// (a > $1) OR (a = $1 AND b < $2) OR (a = $1 AND b = $2 AND id > $3)
+func whereKeyset(query string, args []interface{}, spec []filters.SortField, keyset *filters.Keyset) (string, []interface{}, error) {
+	base := len(args)
+
+	for _, field := range spec {
+		raw, ok := keyset.Values[field.Field]
+		if !ok {
+			return "", nil, fmt.Errorf("%w:missing field %s", filters.ErrInvalidKeyset, field.Field)
+		}
+
+		value, err := keysetValue(field.Field, raw)
+		if err != nil {
+			return "", nil, err
+		}
+
+		args = append(args, value)
+	}
+
+	orClauses := make([]string, 0, len(spec))
+
+	for i, field := range spec {
+		andClauses := make([]string, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			andClauses = append(andClauses, fmt.Sprintf("%s = $%d", sortExpr(spec[j].Field), base+j+1))
+		}
+
+		op := ">"
+		if field.Desc != keyset.Backward {
+			op = "<"
+		}
+
+		andClauses = append(andClauses, fmt.Sprintf("%s %s $%d", sortExpr(field.Field), op, base+i+1))
+
+		orClauses = append(orClauses, "("+strings.Join(andClauses, " AND ")+")")
+	}
+
+	// query is always built by filter, so WHERE is already there
+	return query + " AND (" + strings.Join(orClauses, " OR ") + ")", args, nil
+}
+
+// keysetValue converts value decoded from cursor JSON to column type
+func keysetValue(column string, raw any) (any, error) {
+	switch column {
+	case IdColumn, AgeColumn:
+		switch v := raw.(type) {
+		case float64:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case int:
+			return int64(v), nil
+		}
+	case CreatedColumn, UpdatedColum:
+		switch v := raw.(type) {
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, fmt.Errorf("%w:%s:%w", filters.ErrInvalidKeyset, column, err)
+			}
+			return t, nil
+		case time.Time:
+			return v, nil
+		}
+	default:
+		if v, ok := raw.(string); ok {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w:bad value of %s", filters.ErrInvalidKeyset, column)
+}
+
+// scanPerson scans personColumns and then extra columns of query if any
+func scanPerson(row pgx.Row, p *StoragePerson, extra ...any) error {
+	return row.Scan(append([]any{
+		&p.ID,
+		&p.Name,
+		&p.Surname,
+		&p.Patronymic,
+		&p.Age,
+		&p.Gender,
+		&p.Nationality,
+		&p.Nationalities,
+		&p.CountryHint,
+		&p.EnrichmentStatus,
+		&p.Enrichment,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.Version,
+	}, extra...)...)
+}
+
+func (p StoragePerson) model() *models.Person {
+	return &models.Person{
+		ID:               p.ID,
+		Name:             p.Name,
+		Surname:          p.Surname,
+		Patronymic:       p.Patronymic,
+		Age:              value(p.Age),
+		Gender:           value(p.Gender),
+		Nationality:      value(p.Nationality),
+		Nationalities:    p.Nationalities,
+		CountryHint:      value(p.CountryHint),
+		EnrichmentStatus: p.EnrichmentStatus,
+		Enrichment:       p.Enrichment,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
+		Version:          p.Version,
+	}
+}
+
+func (p StoragePerson) keyset(spec []filters.SortField, backward bool) *filters.Keyset {
+	values := make(map[string]any, len(spec))
+
+	for _, field := range spec {
+		switch field.Field {
+		case IdColumn:
+			values[field.Field] = p.ID
+		case NameColumn:
+			values[field.Field] = p.Name
+		case SurnameColumn:
+			values[field.Field] = p.Surname
+		case PatronymicColumn:
+			values[field.Field] = p.Patronymic
+		case AgeColumn:
+			values[field.Field] = value(p.Age)
+		case GenderColumn:
+			values[field.Field] = value(p.Gender)
+		case NationalityColumn:
+			values[field.Field] = value(p.Nationality)
+		case CreatedColumn:
+			values[field.Field] = p.CreatedAt
+		case UpdatedColum:
+			values[field.Field] = p.UpdatedAt
+		}
+	}
+
+	return &filters.Keyset{Values: values, Backward: backward}
+}
+
+// value returns value of nullable column or zero value for null
+func value[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+
+	return *v
+}
+
+// nullable returns nil for zero value, so it's stored as null
+func nullable[T comparable](v T) any {
+	var zero T
+	if v == zero {
+		return nil
+	}
+
+	return v
+}
+
+// enrichmentValue - enrichment column is not null
+func enrichmentValue(p *models.Person) map[string]*models.AttributeEnrichment {
+	if p.Enrichment == nil {
+		return map[string]*models.AttributeEnrichment{}
+	}
+
+	return p.Enrichment
+}
+
+// sortExpr returns expression to sort by column
+func sortExpr(column string) string {
+	if expr, ok := nullableColumns[column]; ok {
+		return expr
+	}
+
+	return column
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"errors"
+	"reflect"
+	"test-task/internal/domain/filters"
+	"testing"
+)
+
+func Test_whereKeyset(t *testing.T) {
+	spec := []filters.SortField{
+		{Field: SurnameColumn},
+		{Field: AgeColumn, Desc: true},
+		{Field: IdColumn},
+	}
+
+	values := map[string]any{
+		SurnameColumn: "Ivanov",
+		AgeColumn:     float64(30),
+		IdColumn:      float64(7),
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		args      []interface{}
+		keyset    *filters.Keyset
+		wantQuery string
+		wantArgs  []interface{}
+		wantErr   error
+	}{
+		{
+			name:      "forward without filters",
+			query:     "SELECT * FROM people WHERE deleted_at IS NULL",
+			keyset:    &filters.Keyset{Values: values},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL AND ((surname > $1) OR (surname = $1 AND COALESCE(age, 0) < $2) OR (surname = $1 AND COALESCE(age, 0) = $2 AND id > $3))",
+			wantArgs:  []interface{}{"Ivanov", int64(30), int64(7)},
+		},
+		{
+			name:      "backward with filters",
+			query:     "SELECT * FROM people WHERE deleted_at IS NULL AND gender = $1",
+			args:      []interface{}{"male"},
+			keyset:    &filters.Keyset{Values: values, Backward: true},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL AND gender = $1 AND ((surname < $2) OR (surname = $2 AND COALESCE(age, 0) > $3) OR (surname = $2 AND COALESCE(age, 0) = $3 AND id < $4))",
+			wantArgs:  []interface{}{"male", "Ivanov", int64(30), int64(7)},
+		},
+		{
+			name:    "missing value",
+			query:   "SELECT * FROM people",
+			keyset:  &filters.Keyset{Values: map[string]any{SurnameColumn: "Ivanov"}},
+			wantErr: filters.ErrInvalidKeyset,
+		},
+		{
+			name:    "wrong value type",
+			query:   "SELECT * FROM people",
+			keyset:  &filters.Keyset{Values: map[string]any{SurnameColumn: "Ivanov", AgeColumn: "old", IdColumn: float64(7)}},
+			wantErr: filters.ErrInvalidKeyset,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery, gotArgs, err := whereKeyset(tt.query, tt.args, spec, tt.keyset)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("whereKeyset() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotQuery != tt.wantQuery {
+				t.Errorf("whereKeyset() query = %v, want %v", gotQuery, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("whereKeyset() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func Test_sortSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    []filters.SortField
+		want    []filters.SortField
+		wantErr bool
+	}{
+		{
+			name: "id tiebreaker",
+			sort: []filters.SortField{{Field: AgeColumn, Desc: true}},
+			want: []filters.SortField{{Field: AgeColumn, Desc: true}, {Field: IdColumn}},
+		},
+		{
+			name: "explicit id",
+			sort: []filters.SortField{{Field: IdColumn, Desc: true}},
+			want: []filters.SortField{{Field: IdColumn, Desc: true}},
+		},
+		{
+			name:    "not whitelisted",
+			sort:    []filters.SortField{{Field: "age; DROP TABLE people"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortSpec(tt.sort)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sortSpec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortSpec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_filter(t *testing.T) {
+	ivan := "Ivan"
+	petr := "pe_tr"
+	ru := "RU"
+	probability := 0.2
+
+	tests := []struct {
+		name      string
+		options   *filters.Options
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "no filters",
+			options:   &filters.Options{},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL",
+		},
+		{
+			name:      "exact",
+			options:   &filters.Options{Name: &ivan},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL AND name = $1",
+			wantArgs:  []interface{}{"Ivan"},
+		},
+		{
+			name:      "exact ignore case",
+			options:   &filters.Options{Name: &ivan, IgnoreCase: true},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL AND name ILIKE $1",
+			wantArgs:  []interface{}{"Ivan"},
+		},
+		{
+			name:      "prefix and contains",
+			options:   &filters.Options{NamePrefix: &ivan, SurnameContains: &petr},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL AND name ILIKE $1 AND surname ILIKE $2",
+			wantArgs:  []interface{}{"Ivan%", `%pe\_tr%`},
+		},
+		{
+			name:      "nationality",
+			options:   &filters.Options{Nationality: &ru},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL AND nationality = $1",
+			wantArgs:  []interface{}{"RU"},
+		},
+		{
+			name:    "nationality candidate",
+			options: &filters.Options{Name: &ivan, Nationality: &ru, NationalityProbability: &probability},
+			wantQuery: "SELECT * FROM people WHERE deleted_at IS NULL AND name = $1 AND (nationality = $2 OR EXISTS " +
+				"(SELECT 1 FROM people_nationalities WHERE person_id = people.id AND country_id = $2 AND probability >= $3))",
+			wantArgs: []interface{}{"Ivan", "RU", 0.2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotQuery, gotArgs := filter("SELECT * FROM people", tt.options)
+			if gotQuery != tt.wantQuery {
+				t.Errorf("filter() query = %v, want %v", gotQuery, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("filter() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func Test_searchQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "two words", text: "Ivanov Petr", want: "ivanov:* & petr:*"},
+		{name: "cyrillic", text: "  Иванов  ", want: "иванов:*"},
+		{name: "tsquery syntax", text: "ivan & !petr:* | (", want: "ivan:* & petr:*"},
+		{name: "empty", text: " & ", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchQuery(tt.text); got != tt.want {
+				t.Errorf("searchQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test-task/internal/domain/models"
+	"test-task/internal/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ClaimEnrichment takes up to limit pending people for enrichment.
+// Claimed people are processing till leaseUntil, then they can be claimed again
+// (worker crashed). SKIP LOCKED lets workers claim in parallel
+func (s *PostgreStorage) ClaimEnrichment(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.EnrichmentTask, error) {
+	query := fmt.Sprintf(`
+	UPDATE %s
+	SET %s = '%s', %s = %s + 1, %s = ($2)
+	WHERE %s IN (
+		SELECT %s FROM %s
+		WHERE %s IS NULL AND %s IN ('%s', '%s') AND %s <= CURRENT_TIMESTAMP
+		ORDER BY %s
+		LIMIT ($1)
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING %s, %s
+	`, PeopleTable,
+		EnrichmentStatusColumn, models.EnrichmentProcessing,
+		EnrichmentAttemptsColumn, EnrichmentAttemptsColumn,
+		EnrichmentNextColumn,
+		IdColumn,
+		IdColumn, PeopleTable,
+		DeletedColumn, EnrichmentStatusColumn, models.EnrichmentPending, models.EnrichmentProcessing, EnrichmentNextColumn,
+		EnrichmentNextColumn,
+		personColumns, EnrichmentAttemptsColumn,
+	)
+
+	rows, err := s.conn.Query(ctx, query, limit, leaseUntil)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return nil, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*models.EnrichmentTask, 0, limit)
+
+	for rows.Next() {
+		var p StoragePerson
+		var attempt int
+
+		if err := scanPerson(rows, &p, &attempt); err != nil {
+			s.log.Error("can't scan person", "err", err.Error())
+
+			return nil, fmt.Errorf("%w:%w", ErrQuery, err)
+		}
+
+		tasks = append(tasks, &models.EnrichmentTask{Person: p.model(), Attempt: attempt})
+	}
+
+	if err := rows.Err(); err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+
+		return nil, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	return tasks, nil
+}
+
+// CompleteEnrichment writes enriched attributes and entity.EnrichmentStatus of claimed person.
+// Pending person is claimed again at retryAt.
+// Returns storage.ErrConflict if person was changed after claim
+func (s *PostgreStorage) CompleteEnrichment(ctx context.Context, entity *models.Person, retryAt time.Time) error {
+	tx, err := s.conn.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		s.log.Error(ErrTxBegin.Error(), "err", err.Error())
+
+		return fmt.Errorf("%w:%w", ErrTxBegin, err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	oldQuery := fmt.Sprintf(`
+	SELECT %s FROM %s
+	WHERE %s = ($1) AND %s IS NULL
+	FOR UPDATE
+	`, personColumns,
+		PeopleTable,
+		IdColumn, DeletedColumn,
+	)
+
+	var old StoragePerson
+
+	err = scanPerson(tx.QueryRow(ctx, oldQuery, entity.ID), &old)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.log.Debug("ID was not found")
+			return storage.ErrIDNotFound
+		}
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", oldQuery)
+
+		return fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	if old.Version != entity.Version || old.EnrichmentStatus != models.EnrichmentProcessing {
+		s.log.Debug("person changed while enriching", "id", entity.ID, "version", old.Version, "status", old.EnrichmentStatus)
+		return fmt.Errorf("%w:version is %d, status is %s", storage.ErrConflict, old.Version, old.EnrichmentStatus)
+	}
+
+	// written before update, so it returns new candidates
+	err = s.saveNationalities(ctx, tx, entity)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+	UPDATE %s
+	SET %s = ($1), %s = ($2), %s = ($3), %s = ($4), %s = ($5), %s = ($6), %s = ($7), %s = %s + 1
+	WHERE %s = ($8)
+	RETURNING %s
+	`, PeopleTable,
+		AgeColumn, GenderColumn, NationalityColumn,
+		EnrichmentColumn, EnrichmentStatusColumn, EnrichmentNextColumn, ReviewColumn,
+		VersionColumn, VersionColumn,
+		IdColumn,
+		personColumns,
+	)
+
+	var updated StoragePerson
+
+	err = scanPerson(tx.QueryRow(ctx, query,
+		nullable(entity.Age),
+		nullable(entity.Gender),
+		nullable(entity.Nationality),
+		enrichmentValue(entity),
+		entity.EnrichmentStatus,
+		retryAt,
+		entity.NeedsReview(),
+		entity.ID,
+	), &updated)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	err = s.recordHistory(ctx, tx, entity.ID, models.OperationUpdate, old.model(), updated.model())
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		s.log.Error(ErrTxCommit.Error(), "err", err.Error())
+
+		return fmt.Errorf("%w:%w", ErrTxCommit, err)
+	}
+
+	*entity = *updated.model()
+
+	return nil
+}
+
+// RetryEnrichment returns claimed person to queue till retryAt
+func (s *PostgreStorage) RetryEnrichment(ctx context.Context, id int64, retryAt time.Time) error {
+	return s.releaseEnrichment(ctx, id, models.EnrichmentPending, retryAt)
+}
+
+// FailEnrichment stops enrichment of claimed person, attributes stay empty
+func (s *PostgreStorage) FailEnrichment(ctx context.Context, id int64) error {
+	return s.releaseEnrichment(ctx, id, models.EnrichmentFailed, time.Now())
+}
+
+func (s *PostgreStorage) releaseEnrichment(ctx context.Context, id int64, status string, nextAt time.Time) error {
+	query := fmt.Sprintf(`
+	UPDATE %s
+	SET %s = ($2), %s = ($3)
+	WHERE %s = ($1) AND %s = '%s'
+	`, PeopleTable,
+		EnrichmentStatusColumn, EnrichmentNextColumn,
+		IdColumn, EnrichmentStatusColumn, models.EnrichmentProcessing,
+	)
+
+	_, err := s.conn.Exec(ctx, query, id, status, nextAt)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	return nil
+}
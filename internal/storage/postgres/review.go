@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"test-task/internal/domain/models"
+)
+
+// ReviewPages returns people with enriched values below threshold, the oldest first
+func (s *PostgreStorage) ReviewPages(ctx context.Context, offset int, limit int) ([]*models.Person, int, error) {
+
+	list := []*models.Person{}
+
+	countQuery := fmt.Sprintf(`
+	SELECT COUNT(*) FROM %s
+	WHERE %s AND %s IS NULL
+	`, PeopleTable,
+		ReviewColumn, DeletedColumn,
+	)
+
+	var count int
+
+	err := s.conn.QueryRow(ctx, countQuery).Scan(&count)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", countQuery)
+
+		return nil, 0, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT %s FROM %s
+	WHERE %s AND %s IS NULL
+	ORDER BY %s
+	OFFSET ($1) LIMIT ($2)
+	`, personColumns,
+		PeopleTable,
+		ReviewColumn, DeletedColumn,
+		IdColumn,
+	)
+
+	rows, err := s.conn.Query(ctx, query, offset, limit)
+	if err != nil {
+		s.log.Error(ErrQuery.Error(), "err", err.Error())
+		s.log.Debug(ErrQuery.Error(), "err", err.Error(), "query", query)
+
+		return nil, 0, fmt.Errorf("%w:%w", ErrQuery, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p StoragePerson
+
+		err := scanPerson(rows, &p)
+		if err != nil {
+			s.log.Error("can't scan row", "err", err.Error())
+			return nil, 0, fmt.Errorf("can't scan row: %w", err)
+		}
+		list = append(list, p.model())
+	}
+
+	if err := rows.Err(); err != nil {
+		s.log.Error("rows error", "err", err.Error())
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return list, count, nil
+}
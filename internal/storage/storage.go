@@ -5,18 +5,34 @@ import (
 	"errors"
 	"test-task/internal/domain/filters"
 	"test-task/internal/domain/models"
+	"time"
 )
 
 var (
 	ErrIDNotFound = errors.New("ID not found")
+	ErrConflict   = errors.New("version conflict")
+	ErrCacheMiss  = errors.New("not cached")
 )
 
 type Storage interface {
 	Save(ctx context.Context, entity *models.Person) (int64, error)
+	SaveBatch(ctx context.Context, entities []*models.Person) ([]int64, error)
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
 	FindByID(ctx context.Context, id int64) (*models.Person, error)
+	History(ctx context.Context, personID int64) ([]*models.PersonChange, error)
 	Update(ctx context.Context, entity *models.Person, id int64) error
 	FilteredPages(ctx context.Context, offset int, limit int, options *filters.Options) ([]*models.Person, int, error)
+	Search(ctx context.Context, text string, offset int, limit int) ([]*models.Person, int, error)
+	ReviewPages(ctx context.Context, offset int, limit int) ([]*models.Person, int, error)
+	KeysetPages(ctx context.Context, keyset *filters.Keyset, limit int, options *filters.Options) ([]*models.Person, *filters.Keyset, *filters.Keyset, error)
+	GetEnrichment(ctx context.Context, name string, attribute string, country string, fetchedAfter time.Time) (*models.Enrichment, error)
+	SaveEnrichment(ctx context.Context, enrichment *models.Enrichment) error
+	ClaimEnrichment(ctx context.Context, limit int, leaseUntil time.Time) ([]*models.EnrichmentTask, error)
+	CompleteEnrichment(ctx context.Context, entity *models.Person, retryAt time.Time) error
+	RetryEnrichment(ctx context.Context, id int64, retryAt time.Time) error
+	FailEnrichment(ctx context.Context, id int64) error
 	Close()
 	Ping(ctx context.Context) error
 }
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS trg_people_updated_at ON people;
+DROP FUNCTION IF EXISTS set_updated_at();
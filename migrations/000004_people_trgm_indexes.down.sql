@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_people_name_trgm;
+DROP INDEX IF EXISTS idx_people_surname_trgm;
+DROP INDEX IF EXISTS idx_people_patronymic_trgm;
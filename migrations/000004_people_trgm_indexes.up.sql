@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_people_name_trgm ON people USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_people_surname_trgm ON people USING GIN (surname gin_trgm_ops);
+CREATE INDEX idx_people_patronymic_trgm ON people USING GIN (patronymic gin_trgm_ops);
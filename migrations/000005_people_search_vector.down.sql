@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_people_search_vector;
+ALTER TABLE people DROP COLUMN IF EXISTS search_vector;
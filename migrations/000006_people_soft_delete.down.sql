@@ -0,0 +1,3 @@
+DELETE FROM people WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_people_deleted_at;
+ALTER TABLE people DROP COLUMN IF EXISTS deleted_at;
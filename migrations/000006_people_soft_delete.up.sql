@@ -0,0 +1,3 @@
+ALTER TABLE people ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_people_deleted_at ON people(deleted_at) WHERE deleted_at IS NOT NULL;
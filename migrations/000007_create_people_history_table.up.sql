@@ -0,0 +1,12 @@
+CREATE TABLE people_history (
+    id BIGSERIAL PRIMARY KEY,
+    person_id INTEGER NOT NULL,
+    operation VARCHAR(16) NOT NULL,
+    old_values JSONB,
+    new_values JSONB,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_people_history_person_id ON people_history(person_id, changed_at);
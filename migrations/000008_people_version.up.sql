@@ -0,0 +1 @@
+ALTER TABLE people ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,8 @@
+CREATE TABLE enrichment_cache (
+    name VARCHAR(50) NOT NULL,
+    attribute VARCHAR(32) NOT NULL,
+    value VARCHAR(64) NOT NULL,
+    probability DOUBLE PRECISION NOT NULL DEFAULT 0,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (name, attribute)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_people_enrichment_queue;
+
+ALTER TABLE people
+    DROP COLUMN enrichment_status,
+    DROP COLUMN enrichment_attempts,
+    DROP COLUMN enrichment_next_at;
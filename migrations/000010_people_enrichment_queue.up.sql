@@ -0,0 +1,7 @@
+ALTER TABLE people
+    ADD COLUMN enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done',
+    ADD COLUMN enrichment_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN enrichment_next_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_people_enrichment_queue ON people(enrichment_next_at)
+    WHERE enrichment_status IN ('pending', 'processing');
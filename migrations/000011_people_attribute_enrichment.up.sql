@@ -0,0 +1,6 @@
+ALTER TABLE people ADD COLUMN enrichment JSONB NOT NULL DEFAULT '{}';
+
+-- не обогащенные атрибуты теперь NULL
+UPDATE people SET age = NULL WHERE age = 0;
+UPDATE people SET gender = NULL WHERE gender = '';
+UPDATE people SET nationality = NULL WHERE nationality = '';
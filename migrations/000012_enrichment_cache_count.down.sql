@@ -0,0 +1 @@
+ALTER TABLE enrichment_cache DROP COLUMN count;
@@ -0,0 +1 @@
+ALTER TABLE enrichment_cache ADD COLUMN count INTEGER NOT NULL DEFAULT 0;
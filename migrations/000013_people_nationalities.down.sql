@@ -0,0 +1,3 @@
+DROP TABLE people_nationalities;
+
+ALTER TABLE enrichment_cache DROP COLUMN candidates;
@@ -0,0 +1,13 @@
+ALTER TABLE enrichment_cache ADD COLUMN candidates JSONB NOT NULL DEFAULT '[]';
+
+-- в кэше нет кандидатов, национальность запросим заново
+DELETE FROM enrichment_cache WHERE attribute = 'nationality';
+
+CREATE TABLE people_nationalities (
+    person_id INTEGER NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    country_id VARCHAR(2) NOT NULL,
+    probability DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (person_id, country_id)
+);
+
+CREATE INDEX idx_people_nationalities_country ON people_nationalities(country_id, probability);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_people_review;
+
+ALTER TABLE people DROP COLUMN needs_review;
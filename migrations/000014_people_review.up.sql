@@ -0,0 +1,3 @@
+ALTER TABLE people ADD COLUMN needs_review BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_people_review ON people(id) WHERE needs_review AND deleted_at IS NULL;
@@ -0,0 +1,6 @@
+DELETE FROM enrichment_cache WHERE country_id <> '';
+ALTER TABLE enrichment_cache DROP CONSTRAINT enrichment_cache_pkey;
+ALTER TABLE enrichment_cache ADD PRIMARY KEY (name, attribute);
+ALTER TABLE enrichment_cache DROP COLUMN country_id;
+
+ALTER TABLE people DROP COLUMN country_hint;
@@ -0,0 +1,6 @@
+ALTER TABLE people ADD COLUMN country_hint VARCHAR(2);
+
+-- ответы agify и genderize зависят от страны
+ALTER TABLE enrichment_cache ADD COLUMN country_id VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE enrichment_cache DROP CONSTRAINT enrichment_cache_pkey;
+ALTER TABLE enrichment_cache ADD PRIMARY KEY (name, attribute, country_id);
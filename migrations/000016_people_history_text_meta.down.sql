@@ -0,0 +1,2 @@
+ALTER TABLE people_history ALTER COLUMN request_id TYPE VARCHAR(64) USING left(request_id, 64);
+ALTER TABLE people_history ALTER COLUMN actor TYPE VARCHAR(100) USING left(actor, 100);
@@ -0,0 +1,3 @@
+-- request_id и actor приходят из заголовков клиента, длина не ограничена
+ALTER TABLE people_history ALTER COLUMN request_id TYPE TEXT;
+ALTER TABLE people_history ALTER COLUMN actor TYPE TEXT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_people_age_sort;
+DROP INDEX IF EXISTS idx_people_gender_sort;
+DROP INDEX IF EXISTS idx_people_nationality_sort;
@@ -0,0 +1,5 @@
+-- сортировка и курсор по атрибутам идут по COALESCE (NULL как нулевое значение),
+-- обычные индексы по age, gender, nationality для них не подходят. id - последнее поле сортировки
+CREATE INDEX idx_people_age_sort ON people ((COALESCE(age, 0)), id);
+CREATE INDEX idx_people_gender_sort ON people ((COALESCE(gender, '')), id);
+CREATE INDEX idx_people_nationality_sort ON people ((COALESCE(nationality, '')), id);